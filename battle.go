@@ -4,121 +4,475 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"strings"
 	"time"
 )
 
+// BattleResult is the outcome of a battle. Initial holds the fleets as they
+// went in and Fleets as they came out.
 type BattleResult struct {
 	Winner    string
 	Survivors []Spaceship
 	Rounds    []BattleRound
+	Initial   []Fleet
+	Fleets    []Fleet
+	Sides     [][]string
+	Factions  []FactionOutcome
+	Retreats  []Retreat
 }
 
+// BattleRound is one round of a battle. Ships holds the state of every ship
+// once the round is over.
 type BattleRound struct {
-	RoundNumber int
-	Attacks     []Attack
+	RoundNumber  int
+	RangeChanges []RangeChange
+	Attacks      []Attack
+	Ships        []ShipState
+}
+
+// ShipState is a ship's condition at one point in a battle.
+type ShipState struct {
+	ID        string
+	Hull      int
+	Shields   int
+	Destroyed bool
 }
 
 type Attack struct {
-	Attacker string
-	Target   string
-	Damage   int
-	Hit      bool
+	Attacker    string
+	Target      string
+	Damage      int
+	Hit         bool
+	Rule        string
+	Weapon      string
+	Range       string
+	Intercepted bool
+	Pursuit     bool
+}
+
+// FactionOutcome is how the battle went for one player.
+type FactionOutcome struct {
+	Player         string
+	Side           int
+	Outcome        string
+	ShipsStart     int
+	ShipsLost      int
+	ShipsDestroyed int
+	Survivors      []Spaceship
+}
+
+const (
+	OutcomeVictory = "victory"
+	OutcomeDefeat  = "defeat"
+	OutcomeDraw    = "draw"
+	OutcomeRetreat = "retreated"
+)
+
+// How the shots in a round take effect. Sequential rounds resolve each shot
+// as it is fired, in speed order, so ships destroyed earlier in the round
+// don't fire. Simultaneous rounds let every ship that started the round
+// fire, and apply the damage once the round is over.
+const (
+	ResolutionSequential   = "sequential"
+	ResolutionSimultaneous = "simultaneous"
+)
+
+func validResolution(resolution string) bool {
+	return resolution == ResolutionSequential || resolution == ResolutionSimultaneous
+}
+
+// BattleConfig controls a battle. Hostile decides who shoots at whom; by
+// default every player is hostile to every other. Rand, if set, makes the
+// battle reproducible. Ranges turns on engagement ranges, so that weapons
+// only fire at fleets within their reach. CanRetreat decides whether a fleet
+// has somewhere to retreat to; by default every fleet does. Resolution is
+// sequential unless set to simultaneous.
+type BattleConfig struct {
+	Hostile    func(playerA, playerB string) bool
+	Rand       *rand.Rand
+	MaxRounds  int
+	Ranges     bool
+	CanRetreat func(fleet Fleet) bool
+	Resolution string
 }
 
+// RunSpaceBattle fights two fleets until one is destroyed or 100 rounds
+// have passed. The fleets passed in are left untouched; their state after
+// the battle is in the result's Fleets.
 func RunSpaceBattle(fleet1, fleet2 Fleet) BattleResult {
-	rand.Seed(time.Now().UnixNano())
+	return RunBattle([]Fleet{fleet1, fleet2}, BattleConfig{})
+}
+
+// RunBattle fights any number of fleets belonging to any number of players.
+// Every ship fires each of its weapons in speed order each round at a ship
+// of a player hostile to its owner, chosen by the targeting strategy of its
+// fleet's doctrine, until no hostile ships face each other or the round
+// limit is reached. The config's resolution decides whether damage lands
+// as each shot is fired or at the end of the round. A fleet whose morale
+// breaks, or whose hull falls to its retreat threshold, leaves the battle
+// at the end of the round, taking a parting shot from every faster enemy
+// ship. Players who aren't hostile to one another are counted as one side.
+// The fleets passed in are left untouched; their state after the battle is
+// in the result's Fleets.
+func RunBattle(fleets []Fleet, config BattleConfig) BattleResult {
+	if config.Hostile == nil {
+		config.Hostile = func(playerA, playerB string) bool { return playerA != playerB }
+	}
+	if config.Rand == nil {
+		config.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if config.MaxRounds == 0 {
+		config.MaxRounds = 100
+	}
+	if config.CanRetreat == nil {
+		config.CanRetreat = func(fleet Fleet) bool { return true }
+	}
+	rng := config.Rand
 	
 	result := BattleResult{
 		Winner:    "",
 		Survivors: []Spaceship{},
 		Rounds:    []BattleRound{},
+		Initial:   make([]Fleet, len(fleets)),
+		Fleets:    make([]Fleet, len(fleets)),
+		Retreats:  []Retreat{},
 	}
 	
-	roundNumber := 1
-	maxRounds := 100
-	
-	for roundNumber <= maxRounds {
-		if fleet1.IsDefeated() || fleet2.IsDefeated() {
-			break
+	var ships []*Spaceship
+	strategies := make(map[*Spaceship]TargetingStrategy)
+	fleetOf := make(map[*Spaceship]int)
+	for i, fleet := range fleets {
+		result.Initial[i] = fleet
+		result.Initial[i].Ships = append([]Spaceship{}, fleet.Ships...)
+		result.Fleets[i] = fleet
+		result.Fleets[i].Ships = append([]Spaceship{}, fleet.Ships...)
+		for j := range result.Fleets[i].Ships {
+			ship := &result.Fleets[i].Ships[j]
+			ships = append(ships, ship)
+			strategies[ship] = TargetingStrategyFor(fleet.Doctrine)
+			fleetOf[ship] = i
 		}
-		
-		round := BattleRound{
-			RoundNumber: roundNumber,
-			Attacks:     []Attack{},
+	}
+	
+	var ranges *battleRanges
+	if config.Ranges {
+		ranges = newBattleRanges(result.Fleets, config.Hostile)
+	}
+	
+	// inReach narrows the enemies down to those a weapon can fire at
+	inReach := func(attacker *Spaceship, enemies []*Spaceship, weapon WeaponType) []*Spaceship {
+		if ranges == nil {
+			return enemies
 		}
-		
-		allShips := append(fleet1.GetAliveShips(), fleet2.GetAliveShips()...)
-		sort.Slice(allShips, func(i, j int) bool {
-			return allShips[i].Speed > allShips[j].Speed
-		})
-		
-		for _, attacker := range allShips {
-			if !attacker.IsAlive() {
-				continue
+		var reachable []*Spaceship
+		for _, enemy := range enemies {
+			if weapon.reaches(ranges.between(fleetOf[attacker], fleetOf[enemy])) {
+				reachable = append(reachable, enemy)
 			}
-			
-			var enemies []Spaceship
-			if attacker.Owner == fleet1.Owner {
-				enemies = fleet2.GetAliveShips()
-			} else {
-				enemies = fleet1.GetAliveShips()
+		}
+		return reachable
+	}
+	
+	morale := newBattleMorale(result.Fleets)
+	retreated := make([]bool, len(fleets))
+	
+	enemiesOf := func(attacker *Spaceship) []*Spaceship {
+		var enemies []*Spaceship
+		for _, ship := range ships {
+			if ship.IsAlive() && !retreated[fleetOf[ship]] && config.Hostile(attacker.Owner, ship.Owner) {
+				enemies = append(enemies, ship)
 			}
-			
+		}
+		return enemies
+	}
+	
+	// damage lands a hit and returns the hull damage it dealt
+	kills := make(map[string]int)
+	damage := func(attacker, target *Spaceship, amount int, weapon WeaponType) int {
+		if !target.IsAlive() {
+			return 0
+		}
+		dealt := target.TakeWeaponDamage(amount, weapon)
+		if !target.IsAlive() {
+			kills[attacker.Owner]++
+			morale.kills[fleetOf[attacker]]++
+		}
+		return dealt
+	}
+	
+	// Hits waiting for the end of a simultaneous round, with the index of
+	// their attack in the round so the damage dealt can be filled in
+	type pendingHit struct {
+		attacker *Spaceship
+		target   *Spaceship
+		damage   int
+		weapon   WeaponType
+		attack   int
+	}
+	var pending []pendingHit
+	simultaneous := config.Resolution == ResolutionSimultaneous
+	
+	// fire has the attacker shoot each of its weapons at one of the ships
+	// returned by targets, which is asked again before every shot. Parting
+	// shots at retreating fleets always land at once.
+	fire := func(round *BattleRound, attacker *Spaceship, targets func() []*Spaceship, pursuit bool) {
+		strategy := strategies[attacker]
+		for _, weapon := range attacker.WeaponList() {
+			enemies := targets()
 			if len(enemies) == 0 {
 				break
 			}
 			
-			target := enemies[rand.Intn(len(enemies))]
+			weaponType := weaponTypes[weapon.Type]
+			enemies = inReach(attacker, enemies, weaponType)
+			if len(enemies) == 0 {
+				continue
+			}
 			
-			hitChance := 0.7
-			hit := rand.Float64() < hitChance
+			target := strategy.SelectTarget(attacker, enemies, rng)
+			hit := rng.Float64() < hitChance(weaponType, attacker, target)
 			
 			attack := Attack{
 				Attacker: attacker.ID,
 				Target:   target.ID,
 				Damage:   0,
 				Hit:      hit,
+				Rule:     strategy.Name(),
+				Weapon:   weapon.Type,
+				Pursuit:  pursuit,
+			}
+			if ranges != nil {
+				attack.Range = ranges.between(fleetOf[attacker], fleetOf[target])
 			}
 			
-			if hit {
-				attack.Damage = attacker.Attack
-				
-				for i := range fleet1.Ships {
-					if fleet1.Ships[i].ID == target.ID {
-						fleet1.Ships[i].TakeDamage(attack.Damage)
-						break
-					}
+			if hit && intercepted(weaponType, target, rng) {
+				attack.Hit = false
+				attack.Intercepted = true
+			} else if hit {
+				if simultaneous && !pursuit {
+					pending = append(pending, pendingHit{attacker, target, weapon.Damage, weaponType, len(round.Attacks)})
+				} else {
+					attack.Damage = damage(attacker, target, weapon.Damage, weaponType)
 				}
-				for i := range fleet2.Ships {
-					if fleet2.Ships[i].ID == target.ID {
-						fleet2.Ships[i].TakeDamage(attack.Damage)
-						break
+			}
+			
+			round.Attacks = append(round.Attacks, attack)
+		}
+	}
+	
+	for roundNumber := 1; roundNumber <= config.MaxRounds; roundNumber++ {
+		var order []*Spaceship
+		engaged := false
+		for _, ship := range ships {
+			if ship.IsAlive() && !retreated[fleetOf[ship]] {
+				order = append(order, ship)
+				engaged = engaged || len(enemiesOf(ship)) > 0
+			}
+		}
+		if !engaged {
+			break
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return order[i].Speed > order[j].Speed
+		})
+		
+		round := BattleRound{
+			RoundNumber: roundNumber,
+			Attacks:     []Attack{},
+		}
+		if ranges != nil {
+			round.RangeChanges = ranges.manoeuvre()
+		}
+		
+		for _, attacker := range order {
+			if !attacker.IsAlive() {
+				continue
+			}
+			fire(&round, attacker, func() []*Spaceship { return enemiesOf(attacker) }, false)
+		}
+		for _, hit := range pending {
+			round.Attacks[hit.attack].Damage = damage(hit.attacker, hit.target, hit.damage, hit.weapon)
+		}
+		pending = nil
+		
+		morale.endRound()
+		for i := range result.Fleets {
+			fleet := &result.Fleets[i]
+			if retreated[i] {
+				continue
+			}
+			reason := morale.retreatReason(i)
+			if reason == "" || !config.CanRetreat(*fleet) {
+				continue
+			}
+			
+			// Faster enemies get a parting shot at the retreating fleet
+			fleeing := func() []*Spaceship {
+				var targets []*Spaceship
+				for j := range fleet.Ships {
+					if fleet.Ships[j].IsAlive() {
+						targets = append(targets, &fleet.Ships[j])
 					}
 				}
+				return targets
+			}
+			speed := fleet.Speed()
+			for _, pursuer := range order {
+				if pursuer.IsAlive() && !retreated[fleetOf[pursuer]] && pursuer.Speed > speed &&
+					config.Hostile(pursuer.Owner, fleet.Owner) {
+					fire(&round, pursuer, fleeing, true)
+				}
 			}
 			
-			round.Attacks = append(round.Attacks, attack)
+			retreated[i] = true
+			if ranges != nil {
+				ranges.withdraw(i)
+			}
+			result.Retreats = append(result.Retreats, Retreat{
+				Fleet:  fleet.ID,
+				Player: fleet.Owner,
+				Round:  roundNumber,
+				Reason: reason,
+			})
 		}
 		
+		for _, ship := range ships {
+			round.Ships = append(round.Ships, ShipState{
+				ID:        ship.ID,
+				Hull:      ship.Hull,
+				Shields:   ship.Shields,
+				Destroyed: !ship.IsAlive(),
+			})
+		}
 		result.Rounds = append(result.Rounds, round)
-		roundNumber++
 	}
 	
-	if fleet1.IsDefeated() && fleet2.IsDefeated() {
+	result.Sides = battleSides(result.Fleets, config.Hostile)
+	result.Factions = factionOutcomes(result.Fleets, result.Sides, kills, retreated, config.Hostile)
+	
+	// The winners are the players left standing with no enemies facing
+	// them. Players who still face enemies, or a field with nobody left on
+	// it, make the battle a draw
+	draw := false
+	var winners []string
+	for _, faction := range result.Factions {
+		switch faction.Outcome {
+		case OutcomeVictory:
+			winners = append(winners, faction.Player)
+			result.Survivors = append(result.Survivors, faction.Survivors...)
+		case OutcomeDraw:
+			draw = true
+		}
+	}
+	if draw || len(winners) == 0 {
 		result.Winner = "Draw"
-	} else if fleet1.IsDefeated() {
-		result.Winner = fleet2.Owner
-		result.Survivors = fleet2.GetAliveShips()
-	} else if fleet2.IsDefeated() {
-		result.Winner = fleet1.Owner
-		result.Survivors = fleet1.GetAliveShips()
+		result.Survivors = nil
 	} else {
-		result.Winner = "Draw"
+		result.Winner = strings.Join(winners, "+")
 	}
 	
 	return result
 }
 
+// battleSides groups the players in a battle into sides: a player joins
+// a side only if they aren't hostile to anyone already on it, so no side
+// ever holds two players at war with each other.
+func battleSides(fleets []Fleet, hostile func(playerA, playerB string) bool) [][]string {
+	var players []string
+	seen := make(map[string]bool)
+	for _, fleet := range fleets {
+		if !seen[fleet.Owner] {
+			seen[fleet.Owner] = true
+			players = append(players, fleet.Owner)
+		}
+	}
+	sort.Strings(players)
+	
+	var sides [][]string
+	assigned := make(map[string]bool)
+	for _, player := range players {
+		if assigned[player] {
+			continue
+		}
+		
+		side := []string{player}
+		assigned[player] = true
+		for _, other := range players {
+			if !assigned[other] && !hostileToAny(side, other, hostile) {
+				side = append(side, other)
+				assigned[other] = true
+			}
+		}
+		sort.Strings(side)
+		sides = append(sides, side)
+	}
+	return sides
+}
+
+func hostileToAny(side []string, player string, hostile func(playerA, playerB string) bool) bool {
+	for _, member := range side {
+		if hostile(member, player) {
+			return true
+		}
+	}
+	return false
+}
+
+// factionOutcomes tallies the battle for every player. A player with ships
+// left on the field wins if no ships hostile to them are left, and draws
+// otherwise; a player whose surviving ships all retreated has retreated,
+// and a player with no ships left is defeated.
+func factionOutcomes(fleets []Fleet, sides [][]string, kills map[string]int, retreated []bool, hostile func(playerA, playerB string) bool) []FactionOutcome {
+	sideOf := make(map[string]int)
+	for i, side := range sides {
+		for _, player := range side {
+			sideOf[player] = i
+		}
+	}
+	
+	outcomes := make(map[string]*FactionOutcome)
+	remaining := make(map[string]int)
+	var players []string
+	for i, fleet := range fleets {
+		outcome := outcomes[fleet.Owner]
+		if outcome == nil {
+			outcome = &FactionOutcome{Player: fleet.Owner, Side: sideOf[fleet.Owner]}
+			outcomes[fleet.Owner] = outcome
+			players = append(players, fleet.Owner)
+		}
+		alive := fleet.GetAliveShips()
+		outcome.ShipsStart += len(fleet.Ships)
+		outcome.ShipsLost += len(fleet.Ships) - len(alive)
+		outcome.Survivors = append(outcome.Survivors, alive...)
+		if !retreated[i] {
+			remaining[fleet.Owner] += len(alive)
+		}
+	}
+	sort.Strings(players)
+	
+	result := make([]FactionOutcome, len(players))
+	for i, player := range players {
+		outcome := outcomes[player]
+		outcome.ShipsDestroyed = kills[player]
+		
+		switch {
+		case len(outcome.Survivors) == 0:
+			outcome.Outcome = OutcomeDefeat
+		case remaining[player] == 0:
+			outcome.Outcome = OutcomeRetreat
+		default:
+			outcome.Outcome = OutcomeVictory
+			for _, other := range outcomes {
+				if remaining[other.Player] > 0 && hostile(player, other.Player) {
+					outcome.Outcome = OutcomeDraw
+				}
+			}
+		}
+		result[i] = *outcome
+	}
+	return result
+}
+
 func PrintBattleResult(result BattleResult) {
 	fmt.Printf("Battle Result: Winner is %s\n", result.Winner)
 	fmt.Printf("Battle lasted %d rounds\n", len(result.Rounds))
@@ -129,4 +483,9 @@ func PrintBattleResult(result BattleResult) {
 			survivor.Name, survivor.ID, survivor.Hull, survivor.MaxHull, 
 			survivor.Shields, survivor.MaxShields)
 	}
-}
\ No newline at end of file
+	
+	for _, faction := range result.Factions {
+		fmt.Printf("  %s: %s, lost %d of %d ships, destroyed %d\n",
+			faction.Player, faction.Outcome, faction.ShipsLost, faction.ShipsStart, faction.ShipsDestroyed)
+	}
+}
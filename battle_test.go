@@ -0,0 +1,157 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// alwaysHits is a random source that always returns 0, so every shot hits
+// and every target pick is the first enemy.
+type alwaysHits struct{}
+
+func (alwaysHits) Int63() int64 { return 0 }
+func (alwaysHits) Seed(int64)   {}
+
+func testFleet(id, owner string, ships ...Spaceship) Fleet {
+	return NewFleet(id, owner, "system_test", ships)
+}
+
+func TestBattleSides(t *testing.T) {
+	fleets := []Fleet{testFleet("fleet_c", "c"), testFleet("fleet_a", "a"), testFleet("fleet_b", "b"), testFleet("fleet_a2", "a")}
+	allied := func(x, y string) func(playerA, playerB string) bool {
+		return func(playerA, playerB string) bool {
+			friends := (playerA == x && playerB == y) || (playerA == y && playerB == x)
+			return playerA != playerB && !friends
+		}
+	}
+	
+	if got, want := battleSides(fleets, allied("", "")), [][]string{{"a"}, {"b"}, {"c"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sides when everyone is hostile = %v, want %v", got, want)
+	}
+	if got, want := battleSides(fleets, allied("c", "a")), [][]string{{"a", "c"}, {"b"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sides with a and c allied = %v, want %v", got, want)
+	}
+	
+	onlyAAndCAtWar := func(playerA, playerB string) bool {
+		return playerA+playerB == "ac" || playerA+playerB == "ca"
+	}
+	if got, want := battleSides(fleets, onlyAAndCAtWar), [][]string{{"a", "b"}, {"c"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sides with only a and c at war = %v, want %v", got, want)
+	}
+}
+
+func TestRunBattleNeutralBetweenEnemies(t *testing.T) {
+	hostile := func(playerA, playerB string) bool {
+		return playerA+playerB == "ac" || playerA+playerB == "ca"
+	}
+	fleets := []Fleet{
+		testFleet("fleet_a", "a", NewSpaceship("ship_a", "Ship", "a", 10, 0, 0, 25, 3)),
+		testFleet("fleet_b", "b", NewSpaceship("ship_b", "Ship", "b", 10, 0, 0, 25, 4)),
+		testFleet("fleet_c", "c", NewSpaceship("ship_c", "Ship", "c", 10, 0, 0, 25, 5)),
+	}
+	
+	result := RunBattle(fleets, BattleConfig{Hostile: hostile, Rand: rand.New(alwaysHits{})})
+	
+	if want := [][]string{{"a", "b"}, {"c"}}; !reflect.DeepEqual(result.Sides, want) {
+		t.Errorf("sides = %v, want %v", result.Sides, want)
+	}
+	// c destroys a before a can fire, and b was never at war with c
+	if result.Winner != "b+c" {
+		t.Errorf("winner = %q, want b+c", result.Winner)
+	}
+	if len(result.Survivors) != 2 {
+		t.Errorf("%d survivors, want 2", len(result.Survivors))
+	}
+}
+
+func TestRunBattleAlliesFightTogether(t *testing.T) {
+	hostile := func(playerA, playerB string) bool {
+		return playerA != playerB && (playerA == "c" || playerB == "c")
+	}
+	fleets := []Fleet{
+		testFleet("fleet_a", "a", NewSpaceship("ship_a", "Ship", "a", 10, 0, 0, 25, 5)),
+		testFleet("fleet_b", "b", NewSpaceship("ship_b", "Ship", "b", 10, 0, 0, 25, 4)),
+		testFleet("fleet_c", "c", NewSpaceship("ship_c", "Ship", "c", 10, 0, 0, 25, 3)),
+	}
+	
+	result := RunBattle(fleets, BattleConfig{Hostile: hostile, Rand: rand.New(alwaysHits{})})
+	
+	if result.Winner != "a+b" {
+		t.Errorf("winner = %q, want a+b", result.Winner)
+	}
+	want := []FactionOutcome{
+		{Player: "a", Side: 0, Outcome: OutcomeVictory, ShipsStart: 1, ShipsDestroyed: 1},
+		{Player: "b", Side: 0, Outcome: OutcomeVictory, ShipsStart: 1},
+		{Player: "c", Side: 1, Outcome: OutcomeDefeat, ShipsStart: 1, ShipsLost: 1},
+	}
+	for i, faction := range result.Factions {
+		faction.Survivors = nil
+		if !reflect.DeepEqual(faction, want[i]) {
+			t.Errorf("faction %d = %+v, want %+v", i, faction, want[i])
+		}
+	}
+	if len(result.Survivors) != 2 {
+		t.Errorf("%d survivors, want the ships of a and b", len(result.Survivors))
+	}
+}
+
+func TestRunBattleFreeForAll(t *testing.T) {
+	fleets := []Fleet{
+		testFleet("fleet_a", "a", NewSpaceship("ship_a", "Fast", "a", 10, 0, 0, 25, 5)),
+		testFleet("fleet_b", "b", NewSpaceship("ship_b", "Medium", "b", 10, 0, 0, 25, 4)),
+		testFleet("fleet_c", "c", NewSpaceship("ship_c", "Slow", "c", 10, 0, 0, 25, 3)),
+	}
+	
+	// a destroys b, then c, firing last, destroys a
+	result := RunBattle(fleets, BattleConfig{Rand: rand.New(alwaysHits{})})
+	
+	if result.Winner != "c" {
+		t.Errorf("winner = %q, want c", result.Winner)
+	}
+	outcomes := make(map[string]string)
+	for _, faction := range result.Factions {
+		outcomes[faction.Player] = faction.Outcome
+	}
+	if want := map[string]string{"a": OutcomeDefeat, "b": OutcomeDefeat, "c": OutcomeVictory}; !reflect.DeepEqual(outcomes, want) {
+		t.Errorf("outcomes = %v, want %v", outcomes, want)
+	}
+	if fleets[0].Ships[0].Hull != 10 {
+		t.Errorf("battle changed the fleets passed in")
+	}
+}
+
+func TestRunBattleResolution(t *testing.T) {
+	// Two ships that destroy each other with a single shot
+	duel := func() []Fleet {
+		return []Fleet{
+			testFleet("fleet_a", "a", NewSpaceship("ship_a", "Fast", "a", 10, 0, 0, 25, 5)),
+			testFleet("fleet_b", "b", NewSpaceship("ship_b", "Slow", "b", 10, 0, 0, 25, 3)),
+		}
+	}
+	want := map[string]struct {
+		winner string
+		shots  int
+	}{
+		ResolutionSequential:   {"a", 1},
+		"":                     {"a", 1},
+		ResolutionSimultaneous: {"Draw", 2},
+	}
+	
+	for resolution, want := range want {
+		result := RunBattle(duel(), BattleConfig{Rand: rand.New(alwaysHits{}), Resolution: resolution})
+		if result.Winner != want.winner {
+			t.Errorf("%q resolution: winner = %q, want %q", resolution, result.Winner, want.winner)
+		}
+		if len(result.Rounds) != 1 || len(result.Rounds[0].Attacks) != want.shots {
+			t.Errorf("%q resolution: rounds = %+v, want one round of %d shots", resolution, result.Rounds, want.shots)
+		}
+		for _, round := range result.Rounds {
+			for _, attack := range round.Attacks {
+				if attack.Damage != 10 {
+					t.Errorf("%q resolution: %s dealt %d damage, want the 10 hull it took", resolution, attack.Attacker, attack.Damage)
+				}
+			}
+		}
+	}
+}
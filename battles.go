@@ -0,0 +1,64 @@
+package main
+
+// battleHistoryTurns is how many turns of battles are kept.
+const battleHistoryTurns = 10
+
+// BattleRecord is a battle kept with the game so it can be looked up and
+// replayed later.
+type BattleRecord struct {
+	ID       string
+	Turn     int
+	SystemID string
+	Result   BattleResult
+}
+
+// recordBattle stores a battle fought this turn and returns its ID. Battles
+// older than battleHistoryTurns are dropped.
+func (gs *GameState) recordBattle(systemID string, result BattleResult) string {
+	kept := []BattleRecord{}
+	for _, battle := range gs.Battles {
+		if battle.Turn > gs.CurrentTurn-battleHistoryTurns {
+			kept = append(kept, battle)
+		}
+	}
+	gs.Battles = kept
+	
+	record := BattleRecord{
+		ID:       gs.newID("battle"),
+		Turn:     gs.CurrentTurn,
+		SystemID: systemID,
+		Result:   result,
+	}
+	gs.Battles = append(gs.Battles, record)
+	return record.ID
+}
+
+func (gs *GameState) findBattle(battleID string) *BattleRecord {
+	for i := range gs.Battles {
+		if gs.Battles[i].ID == battleID {
+			return &gs.Battles[i]
+		}
+	}
+	return nil
+}
+
+// GetBattles lists the battles a player took part in, or every battle if
+// no player is given.
+func (gs *GameState) GetBattles(playerID string) []BattleRecord {
+	var battles []BattleRecord
+	for _, battle := range gs.Battles {
+		if playerID == "" || battle.involves(playerID) {
+			battles = append(battles, battle)
+		}
+	}
+	return battles
+}
+
+func (b BattleRecord) involves(playerID string) bool {
+	for _, faction := range b.Result.Factions {
+		if faction.Player == playerID {
+			return true
+		}
+	}
+	return false
+}
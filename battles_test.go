@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newBattleRecordTestGame() *GameState {
+	gs := &GameState{CurrentTurn: 3}
+	duel := RunBattle([]Fleet{
+		testFleet("fleet_a", "a", NewSpaceship("ship_a", "Ship", "a", 10, 0, 0, 25, 5)),
+		testFleet("fleet_b", "b", NewSpaceship("ship_b", "Ship", "b", 10, 0, 0, 25, 3)),
+	}, BattleConfig{Rand: rand.New(alwaysHits{})})
+	gs.recordBattle("sys_1", duel)
+	
+	gs.CurrentTurn = 4
+	skirmish := RunBattle([]Fleet{
+		testFleet("fleet_b", "b", NewSpaceship("ship_b", "Ship", "b", 10, 0, 0, 25, 3)),
+		testFleet("fleet_c", "c", NewSpaceship("ship_c", "Ship", "c", 10, 0, 0, 25, 5)),
+	}, BattleConfig{Rand: rand.New(alwaysHits{})})
+	gs.recordBattle("sys_2", skirmish)
+	return gs
+}
+
+func TestGetBattles(t *testing.T) {
+	gs := newBattleRecordTestGame()
+	
+	ids := func(battles []BattleRecord) string {
+		var ids []string
+		for _, battle := range battles {
+			ids = append(ids, battle.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+	for player, want := range map[string]string{"": "battle_1,battle_2", "a": "battle_1", "b": "battle_1,battle_2", "d": ""} {
+		if got := ids(gs.GetBattles(player)); got != want {
+			t.Errorf("battles of %q = %s, want %s", player, got, want)
+		}
+	}
+	
+	battle := gs.findBattle("battle_2")
+	if battle == nil || battle.Turn != 4 || battle.SystemID != "sys_2" || battle.Result.Winner != "c" {
+		t.Errorf("battle_2 = %+v", battle)
+	}
+	if gs.findBattle("battle_9") != nil {
+		t.Errorf("found a battle that was never fought")
+	}
+}
+
+func TestHandleBattles(t *testing.T) {
+	server := &GameServer{gameState: newBattleRecordTestGame()}
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		server.handleBattles(recorder, httptest.NewRequest("GET", path, nil))
+		return recorder
+	}
+	
+	var list struct {
+		Success bool
+		Data    []map[string]interface{}
+	}
+	if err := json.NewDecoder(get("/battles?player_id=a").Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if !list.Success || len(list.Data) != 1 || list.Data[0]["winner"] != "a" {
+		t.Errorf("battle list = %+v", list)
+	}
+	
+	var details struct {
+		Success bool
+		Data    struct {
+			Fleets []map[string]interface{}
+			Rounds []map[string]interface{}
+		}
+	}
+	if err := json.NewDecoder(get("/battles/battle_1").Body).Decode(&details); err != nil {
+		t.Fatal(err)
+	}
+	if !details.Success || len(details.Data.Fleets) != 2 || len(details.Data.Rounds) != 1 {
+		t.Errorf("battle details = %+v", details)
+	}
+	
+	replay := get("/battles/battle_1/replay")
+	if !strings.HasPrefix(replay.Header().Get("Content-Type"), "text/html") || !strings.Contains(replay.Body.String(), "ship_a") {
+		t.Errorf("replay is not an HTML page of the battle")
+	}
+	
+	for _, path := range []string{"/battles/battle_9", "/battles/battle_1/summary"} {
+		if body := get(path).Body.String(); !strings.Contains(body, `"success":false`) {
+			t.Errorf("%s = %s, want an error", path, body)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const (
+	bombardKillsPerAttack = 500
+	bombardDamagePerLevel = 60
+)
+
+// processBombardOrder sets a fleet to bombard a planet in its system every
+// turn until it leaves or is told to stop with "stop". Bombarding a planet
+// of anyone the player isn't at war with is an act of war, which also
+// brings in the owner's allies. Allies' planets can't be bombarded.
+func (gs *GameState) processBombardOrder(order Order) {
+	fleetID, _ := order.Parameters["fleet_id"].(string)
+	fleet := gs.findFleet(fleetID)
+	if fleet == nil || fleet.Owner != order.PlayerID {
+		return
+	}
+	
+	if stop, _ := order.Parameters["stop"].(bool); stop {
+		fleet.BombardTarget = ""
+		return
+	}
+	
+	planet := gs.findPlanet(order.PlanetID)
+	if planet == nil || planet.Owner == "" || gs.areAllied(planet.Owner, order.PlayerID) {
+		return
+	}
+	if fleet.InTransit() || fleet.Location != planet.StarSystemID || fleet.TotalAttack() == 0 {
+		return
+	}
+	if gs.hasDefences(planet.StarSystemID, planet.Owner) {
+		gs.report(order.PlayerID, "Can't bombard %s while its defences are still standing", planet.Name)
+		return
+	}
+	
+	if !gs.areHostile(planet.Owner, order.PlayerID) {
+		gs.actOfWar(order.PlayerID, planet.Owner, "the bombardment of "+planet.Name)
+	}
+	fleet.BombardTarget = planet.ID
+	fmt.Printf("Player %s fleet %s begins bombarding %s\n", order.PlayerID, fleet.ID, planet.Name)
+}
+
+// updateBombardment carries out a turn of bombardment for every fleet still
+// in orbit of its target. Damage scales with the fleet's total attack: it
+// kills population and knocks levels off facilities, hitting Barracks first
+// to wear down the garrison.
+func (gs *GameState) updateBombardment() {
+	fmt.Println("Updating bombardment...")
+	
+	for i := range gs.Fleets {
+		fleet := &gs.Fleets[i]
+		if fleet.BombardTarget == "" {
+			continue
+		}
+		
+		planet := gs.findPlanet(fleet.BombardTarget)
+		if planet == nil || fleet.InTransit() || fleet.Location != planet.StarSystemID ||
+			planet.Owner == "" || !gs.areHostile(planet.Owner, fleet.Owner) {
+			fleet.BombardTarget = ""
+			continue
+		}
+		if gs.hasDefences(planet.StarSystemID, planet.Owner) {
+			continue
+		}
+		
+		attack := fleet.TotalAttack()
+		killed := min(planet.Population, int64(attack*bombardKillsPerAttack))
+		planet.Population -= killed
+		levelsLost := planet.damageFacilities(attack / bombardDamagePerLevel)
+		
+		gs.report(fleet.Owner, "Bombarded %s: %d killed, %d facility levels destroyed", planet.Name, killed, levelsLost)
+		gs.report(planet.Owner, "%s is being bombarded by %s: %d killed, %d facility levels destroyed",
+			planet.Name, fleet.Owner, killed, levelsLost)
+	}
+}
+
+// damageFacilities knocks levels off the planet's facilities, Barracks
+// first and the rest at random, removing any that reach level zero. It
+// returns the number of levels lost.
+func (p *Planet) damageFacilities(levels int) int {
+	lost := 0
+	for ; lost < levels && len(p.Facilities) > 0; lost++ {
+		index := rand.Intn(len(p.Facilities))
+		for i, facility := range p.Facilities {
+			if facility.Type == "Barracks" {
+				index = i
+				break
+			}
+		}
+		
+		facility := &p.Facilities[index]
+		facility.Level--
+		facility.Output = facility.Level * 10
+		if facility.Level <= 0 {
+			p.Facilities = append(p.Facilities[:index], p.Facilities[index+1:]...)
+		}
+	}
+	return lost
+}
@@ -0,0 +1,87 @@
+package main
+
+import "testing"
+
+// newBombardTestGame puts a fleet of player a with 120 attack in orbit of a
+// planet of player b.
+func newBombardTestGame() *GameState {
+	planet := NewPlanet("planet_1", "One", "sys", "b", "Terran", 1, 1, true)
+	planet.Population = 100000
+	planet.AddFacility("Mine", 3)
+	planet.AddFacility("Barracks", 1)
+	system := NewStarSystem("sys", "Sys", Star{}, Coordinates{})
+	system.AddPlanet(planet)
+	
+	ships := []Spaceship{
+		NewSpaceship("ship_1", "Gunship", "a", 100, 0, 0, 60, 5),
+		NewSpaceship("ship_2", "Gunship", "a", 100, 0, 0, 60, 5),
+	}
+	return &GameState{
+		Galaxy:    Galaxy{StarSystems: []StarSystem{system}},
+		Players:   []Player{{ID: "a"}, {ID: "b"}, {ID: "c"}},
+		Fleets:    []Fleet{NewFleet("fleet_1", "a", "sys", ships)},
+		Relations: make(map[string]string),
+		Proposals: make(map[string]map[string]string),
+		Reports:   make(map[string][]string),
+	}
+}
+
+func bombardOrder(params map[string]interface{}) Order {
+	params["fleet_id"] = "fleet_1"
+	return Order{PlayerID: "a", PlanetID: "planet_1", Parameters: params}
+}
+
+func TestBombardment(t *testing.T) {
+	gs := newBombardTestGame()
+	gs.processBombardOrder(bombardOrder(map[string]interface{}{}))
+	if gs.Fleets[0].BombardTarget != "planet_1" {
+		t.Fatalf("bombard target = %q, want planet_1", gs.Fleets[0].BombardTarget)
+	}
+	
+	gs.updateBombardment()
+	
+	planet := gs.findPlanet("planet_1")
+	if planet.Population != 40000 {
+		t.Errorf("population = %d, want 40000 after 60000 killed", planet.Population)
+	}
+	if planet.HasFacility("Barracks") {
+		t.Errorf("barracks survived; they should be hit first")
+	}
+	levels := 0
+	for _, facility := range planet.Facilities {
+		levels += facility.Level
+	}
+	if levels != 2 {
+		t.Errorf("%d facility levels left, want 2 of 4", levels)
+	}
+	
+	gs.processBombardOrder(bombardOrder(map[string]interface{}{"stop": true}))
+	gs.updateBombardment()
+	if planet.Population != 40000 {
+		t.Errorf("bombardment carried on after the stop order")
+	}
+}
+
+func TestBombardingIsAnActOfWar(t *testing.T) {
+	gs := newBombardTestGame()
+	gs.setRelation("a", "b", StatusPeace)
+	gs.setRelation("a", "c", StatusNeutral)
+	gs.setRelation("b", "c", StatusAlliance)
+	
+	gs.processBombardOrder(bombardOrder(map[string]interface{}{}))
+	
+	if !gs.areHostile("a", "b") || !gs.areHostile("a", "c") {
+		t.Errorf("relations after bombarding = %v, want war with b and its ally c", gs.GetRelations("a"))
+	}
+}
+
+func TestCannotBombardAllies(t *testing.T) {
+	gs := newBombardTestGame()
+	gs.setRelation("a", "b", StatusAlliance)
+	
+	gs.processBombardOrder(bombardOrder(map[string]interface{}{}))
+	
+	if gs.Fleets[0].BombardTarget != "" || gs.areHostile("a", "b") {
+		t.Errorf("fleet bombarded an ally")
+	}
+}
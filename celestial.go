@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
+	"sort"
 	"time"
 )
 
@@ -31,6 +33,7 @@ type Planet struct {
 	Habitable    bool
 	Atmosphere   string
 	Temperature  int
+	Outpost      bool
 }
 
 type StarSystem struct {
@@ -41,6 +44,9 @@ type StarSystem struct {
 	Coordinates Coordinates
 	Explored    bool
 	ControlledBy string
+	Contested    bool
+	Connections  []string
+	Structures   []Structure
 }
 
 type Coordinates struct {
@@ -136,10 +142,62 @@ func (p *Planet) GetTotalProduction(resourceType string) int {
 	return total
 }
 
+func (p *Planet) HasFacility(facilityType string) bool {
+	for _, facility := range p.Facilities {
+		if facility.Type == facilityType {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Resources) CanAfford(cost Resources) bool {
+	return r.Metals >= cost.Metals &&
+		r.Energy >= cost.Energy &&
+		r.Minerals >= cost.Minerals &&
+		r.Food >= cost.Food &&
+		r.Technology >= cost.Technology
+}
+
+func (r *Resources) Spend(cost Resources) {
+	r.Metals -= cost.Metals
+	r.Energy -= cost.Energy
+	r.Minerals -= cost.Minerals
+	r.Food -= cost.Food
+	r.Technology -= cost.Technology
+}
+
+// field returns the stockpile for a named resource, or nil if the name is
+// not one of ResourceTypes.
+func (r *Resources) field(resource string) *int {
+	switch resource {
+	case "Metals":
+		return &r.Metals
+	case "Energy":
+		return &r.Energy
+	case "Minerals":
+		return &r.Minerals
+	case "Food":
+		return &r.Food
+	case "Technology":
+		return &r.Technology
+	}
+	return nil
+}
+
 func (s *StarSystem) AddPlanet(planet Planet) {
 	s.Planets = append(s.Planets, planet)
 }
 
+func (s *StarSystem) IsConnectedTo(systemID string) bool {
+	for _, connection := range s.Connections {
+		if connection == systemID {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *StarSystem) GetHabitablePlanets() []Planet {
 	var habitable []Planet
 	for _, planet := range s.Planets {
@@ -187,12 +245,13 @@ func CalculateDistance(coord1, coord2 Coordinates) float64 {
 	dx := coord1.X - coord2.X
 	dy := coord1.Y - coord2.Y
 	dz := coord1.Z - coord2.Z
-	return (dx*dx + dy*dy + dz*dz) * 0.5
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
 }
 
 type Player struct {
-	ID   string
-	Name string
+	ID      string
+	Name    string
+	Credits int
 }
 
 func InitializeGalaxy(players []Player, galaxySize int) Galaxy {
@@ -257,6 +316,7 @@ func InitializeGalaxy(players []Player, galaxySize int) Galaxy {
 		
 		homeworld.AddFacility("MetalMine", 2)
 		homeworld.AddFacility("PowerPlant", 2)
+		homeworld.AddFacility("MineralExtractor", 1)
 		homeworld.AddFacility("Farm", 3)
 		homeworld.AddFacility("Factory", 1)
 		
@@ -301,9 +361,79 @@ func InitializeGalaxy(players []Player, galaxySize int) Galaxy {
 		galaxy.AddStarSystem(system)
 	}
 	
+	galaxy.connectSystems()
+	
 	return galaxy
 }
 
+// connectSystems lays hyperlanes between systems. A minimum spanning tree
+// keeps the whole galaxy reachable, and links to each system's nearest
+// neighbours add alternative routes.
+func (g *Galaxy) connectSystems() {
+	count := len(g.StarSystems)
+	if count < 2 {
+		return
+	}
+	
+	distance := func(i, j int) float64 {
+		return CalculateDistance(g.StarSystems[i].Coordinates, g.StarSystems[j].Coordinates)
+	}
+	
+	// Prim's algorithm
+	inTree := make([]bool, count)
+	bestDistance := make([]float64, count)
+	bestLink := make([]int, count)
+	for i := range bestDistance {
+		bestDistance[i] = math.Inf(1)
+		bestLink[i] = -1
+	}
+	bestDistance[0] = 0
+	
+	for n := 0; n < count; n++ {
+		next := -1
+		for i := 0; i < count; i++ {
+			if !inTree[i] && (next == -1 || bestDistance[i] < bestDistance[next]) {
+				next = i
+			}
+		}
+		inTree[next] = true
+		if bestLink[next] >= 0 {
+			g.connect(next, bestLink[next])
+		}
+		
+		for i := 0; i < count; i++ {
+			if !inTree[i] && distance(next, i) < bestDistance[i] {
+				bestDistance[i] = distance(next, i)
+				bestLink[i] = next
+			}
+		}
+	}
+	
+	for i := 0; i < count; i++ {
+		neighbours := make([]int, 0, count-1)
+		for j := 0; j < count; j++ {
+			if j != i {
+				neighbours = append(neighbours, j)
+			}
+		}
+		sort.Slice(neighbours, func(a, b int) bool {
+			return distance(i, neighbours[a]) < distance(i, neighbours[b])
+		})
+		for _, j := range neighbours[:min(2, len(neighbours))] {
+			g.connect(i, j)
+		}
+	}
+}
+
+func (g *Galaxy) connect(i, j int) {
+	a, b := &g.StarSystems[i], &g.StarSystems[j]
+	if a.IsConnectedTo(b.ID) {
+		return
+	}
+	a.Connections = append(a.Connections, b.ID)
+	b.Connections = append(b.Connections, a.ID)
+}
+
 func generateHomeworldCoordinates(playerIndex, totalPlayers, galaxySize int) Coordinates {
 	angle := float64(playerIndex) * 2.0 * 3.14159 / float64(totalPlayers)
 	radius := float64(galaxySize) * 0.3
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resolveCombat fights a battle in every system where a fleet meets fleets
+// or structures of a hostile player, writes the damage back to the fleets
+// and structures, and clears out the wrecks. Every fleet and structure in
+// the system takes part, on sides worked out from diplomatic status.
+func (gs *GameState) resolveCombat() {
+	fmt.Println("Resolving combat...")
+	
+	for i := range gs.Galaxy.StarSystems {
+		system := &gs.Galaxy.StarSystems[i]
+		var participants []Fleet
+		var owners []string
+		for _, fleet := range gs.GetFleetsInSystem(system.ID) {
+			if !fleet.IsDefeated() {
+				participants = append(participants, fleet)
+				owners = append(owners, fleet.Owner)
+			}
+		}
+		
+		// Structures only fight fleets, never each other
+		defences := defenceFleets(system)
+		defenceOwners := make([]string, len(defences))
+		for j, fleet := range defences {
+			defenceOwners[j] = fleet.Owner
+		}
+		if !gs.anyHostile(owners) && !gs.anyHostileBetween(owners, defenceOwners) {
+			continue
+		}
+		participants = append(participants, defences...)
+		
+		result := RunBattle(participants, BattleConfig{
+			Hostile:    gs.areHostile,
+			Ranges:     gs.Rules.TacticalRanges,
+			Resolution: gs.Rules.CombatResolution,
+			CanRetreat: func(fleet Fleet) bool {
+				return gs.retreatDestination(fleet) != ""
+			},
+		})
+		for _, fleet := range result.Fleets {
+			if fleet.Stationary {
+				gs.applyStructureDamage(system, fleet.Ships)
+				continue
+			}
+			gs.applyBattleDamage(fleet.Ships)
+			if f := gs.findFleet(fleet.ID); f != nil {
+				f.Morale = fleet.Morale
+			}
+		}
+		battleID := gs.recordBattle(system.ID, result)
+		gs.reportBattle(*system, battleID, result)
+		for _, retreat := range result.Retreats {
+			gs.retreatFleet(retreat)
+		}
+	}
+	
+	gs.removeDestroyedShips()
+}
+
+// anyHostile reports whether any two of the players are hostile.
+func (gs *GameState) anyHostile(players []string) bool {
+	for i := range players {
+		for j := i + 1; j < len(players); j++ {
+			if gs.areHostile(players[i], players[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// anyHostileBetween reports whether any player in one list is hostile to
+// any player in the other.
+func (gs *GameState) anyHostileBetween(players, others []string) bool {
+	for _, player := range players {
+		for _, other := range others {
+			if gs.areHostile(player, other) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyBattleDamage copies the state of ships after a battle back onto the
+// matching ships in the game's fleets.
+func (gs *GameState) applyBattleDamage(ships []Spaceship) {
+	byID := make(map[string]Spaceship)
+	for _, ship := range ships {
+		byID[ship.ID] = ship
+	}
+	
+	for i := range gs.Fleets {
+		for j := range gs.Fleets[i].Ships {
+			if ship, exists := byID[gs.Fleets[i].Ships[j].ID]; exists {
+				gs.Fleets[i].Ships[j] = ship
+			}
+		}
+	}
+}
+
+func (gs *GameState) reportBattle(system StarSystem, battleID string, result BattleResult) {
+	fmt.Printf("Battle at %s: ", system.Name)
+	PrintBattleResult(result)
+	
+	for _, faction := range result.Factions {
+		var enemies []string
+		for _, other := range result.Factions {
+			if gs.areHostile(faction.Player, other.Player) {
+				enemies = append(enemies, other.Player)
+			}
+		}
+		
+		gs.report(faction.Player, "Battle at %s against %s: %s after %d rounds, losing %d of %d ships and destroying %d (%s)",
+			system.Name, strings.Join(enemies, ", "), faction.Outcome, len(result.Rounds),
+			faction.ShipsLost, faction.ShipsStart, faction.ShipsDestroyed, battleID)
+	}
+}
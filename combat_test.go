@@ -0,0 +1,83 @@
+package main
+
+import "testing"
+
+// newCombatTestGame returns a game with a single system, "sys", and the
+// given fleets.
+func newCombatTestGame(fleets ...Fleet) *GameState {
+	system := NewStarSystem("sys", "Sys", Star{}, Coordinates{})
+	return &GameState{
+		Galaxy:    Galaxy{StarSystems: []StarSystem{system}},
+		Fleets:    fleets,
+		Relations: make(map[string]string),
+		Reports:   make(map[string][]string),
+	}
+}
+
+func TestResolveCombatDestroysLosers(t *testing.T) {
+	// The cruiser can't miss every shot for a hundred rounds
+	cruiser := NewSpaceship("cruiser", "Cruiser", "a", 1000, 0, 0, 100, 5)
+	scout := NewSpaceship("scout", "Scout", "b", 10, 0, 0, 0, 5)
+	gs := newCombatTestGame(
+		NewFleet("fleet_a", "a", "sys", []Spaceship{cruiser}),
+		NewFleet("fleet_b", "b", "sys", []Spaceship{scout}),
+	)
+	
+	gs.resolveCombat()
+	
+	if len(gs.Fleets) != 1 || gs.Fleets[0].ID != "fleet_a" {
+		t.Fatalf("fleets after battle = %+v, want only fleet_a", gs.Fleets)
+	}
+	if hull := gs.Fleets[0].Ships[0].Hull; hull != 1000 {
+		t.Errorf("cruiser hull = %d, want it untouched by an unarmed scout", hull)
+	}
+	if len(gs.Reports["a"]) != 1 || len(gs.Reports["b"]) != 1 {
+		t.Errorf("reports = %v, want one battle report each", gs.Reports)
+	}
+}
+
+func TestResolveCombatNeedsHostileFleets(t *testing.T) {
+	tests := map[string]func(gs *GameState){
+		"at peace": func(gs *GameState) {
+			gs.setRelation("a", "b", StatusPeace)
+		},
+		"one fleet travelling": func(gs *GameState) {
+			gs.Fleets[1].Destination = "elsewhere"
+			gs.Fleets[1].TurnsRemaining = 1
+		},
+	}
+	
+	for name, setup := range tests {
+		t.Run(name, func(t *testing.T) {
+			gs := newCombatTestGame(
+				NewFleet("fleet_a", "a", "sys", []Spaceship{NewSpaceship("ship_a", "Ship", "a", 10, 0, 0, 100, 5)}),
+				NewFleet("fleet_b", "b", "sys", []Spaceship{NewSpaceship("ship_b", "Ship", "b", 10, 0, 0, 100, 5)}),
+			)
+			setup(gs)
+			
+			gs.resolveCombat()
+			
+			if len(gs.Fleets) != 2 || len(gs.Reports) != 0 {
+				t.Errorf("a battle was fought: fleets %+v, reports %v", gs.Fleets, gs.Reports)
+			}
+		})
+	}
+}
+
+func TestResolveCombatJoinsEachPlayersFleets(t *testing.T) {
+	gs := newCombatTestGame(
+		NewFleet("fleet_a1", "a", "sys", []Spaceship{NewSpaceship("ship_a1", "Ship", "a", 1000, 0, 0, 100, 5)}),
+		NewFleet("fleet_a2", "a", "sys", []Spaceship{NewSpaceship("ship_a2", "Ship", "a", 1000, 0, 0, 100, 5)}),
+		NewFleet("fleet_b", "b", "sys", []Spaceship{NewSpaceship("ship_b", "Ship", "b", 10, 0, 0, 0, 5)}),
+	)
+	
+	gs.resolveCombat()
+	
+	// Both of a's fleets fought as one force, in a single battle
+	if len(gs.Reports["a"]) != 1 || len(gs.Reports["b"]) != 1 {
+		t.Errorf("reports = %v, want a single battle", gs.Reports)
+	}
+	if len(gs.Fleets) != 2 {
+		t.Errorf("fleets after battle = %+v, want a's two fleets", gs.Fleets)
+	}
+}
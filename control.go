@@ -0,0 +1,94 @@
+package main
+
+import "fmt"
+
+// systemController works out who controls a system from its planets and
+// structures. A lone starbase owner controls the system outright;
+// otherwise a player controls it by owning every settled planet, or by
+// holding a majority of its population. Anything else with more than one
+// owner present is contested.
+func systemController(system *StarSystem) (controller string, contested bool) {
+	starbaseOwners := make(map[string]bool)
+	planetOwners := make(map[string]bool)
+	population := make(map[string]int64)
+	totalPopulation := int64(0)
+	
+	for _, planet := range system.Planets {
+		if planet.Owner == "" {
+			continue
+		}
+		planetOwners[planet.Owner] = true
+		population[planet.Owner] += planet.Population
+		totalPopulation += planet.Population
+	}
+	for _, structure := range system.Structures {
+		if structure.Type == "Starbase" {
+			starbaseOwners[structure.Owner] = true
+		}
+	}
+	
+	if len(starbaseOwners) == 1 {
+		for owner := range starbaseOwners {
+			return owner, false
+		}
+	}
+	if len(starbaseOwners) > 1 {
+		return "", true
+	}
+	
+	if len(planetOwners) == 0 {
+		return "", false
+	}
+	if len(planetOwners) == 1 {
+		for owner := range planetOwners {
+			return owner, false
+		}
+	}
+	
+	for owner, count := range population {
+		if count*2 > totalPopulation {
+			return owner, false
+		}
+	}
+	return "", true
+}
+
+// updateSystemControl recomputes control of every system and reports any
+// change to the players involved.
+func (gs *GameState) updateSystemControl() {
+	fmt.Println("Updating system control...")
+	
+	for i := range gs.Galaxy.StarSystems {
+		system := &gs.Galaxy.StarSystems[i]
+		controller, contested := systemController(system)
+		
+		if controller != system.ControlledBy {
+			if system.ControlledBy != "" {
+				gs.report(system.ControlledBy, "Lost control of %s", system.Name)
+			}
+			if controller != "" {
+				gs.report(controller, "Gained control of %s", system.Name)
+			}
+			fmt.Printf("Control of %s changed from %q to %q\n", system.Name, system.ControlledBy, controller)
+		}
+		
+		if contested && !system.Contested {
+			for owner := range gs.systemPlanetOwners(system) {
+				gs.report(owner, "%s is contested", system.Name)
+			}
+		}
+		
+		system.ControlledBy = controller
+		system.Contested = contested
+	}
+}
+
+func (gs *GameState) systemPlanetOwners(system *StarSystem) map[string]bool {
+	owners := make(map[string]bool)
+	for _, planet := range system.Planets {
+		if planet.Owner != "" {
+			owners[planet.Owner] = true
+		}
+	}
+	return owners
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testSystem builds a system with one planet per owner given, each with the
+// matching population. An empty owner leaves the planet unsettled.
+func testSystem(owners []string, populations []int64) StarSystem {
+	system := NewStarSystem("system_test", "Test", Star{}, Coordinates{})
+	for i, owner := range owners {
+		planet := NewPlanet(fmt.Sprintf("planet_%d", i), fmt.Sprintf("Planet %d", i), system.ID, owner, "Terran", 1, i+1, true)
+		planet.Population = populations[i]
+		system.AddPlanet(planet)
+	}
+	return system
+}
+
+func TestSystemController(t *testing.T) {
+	tests := []struct {
+		name           string
+		owners         []string
+		populations    []int64
+		starbases      []int
+		wantController string
+		wantContested  bool
+	}{
+		{name: "nobody settled", owners: []string{"", ""}, populations: []int64{0, 0}},
+		{name: "sole owner", owners: []string{"a", "a"}, populations: []int64{100, 50}, wantController: "a"},
+		{name: "sole owner beside unsettled planets", owners: []string{"a", ""}, populations: []int64{10, 0}, wantController: "a"},
+		{name: "sole owner of an outpost", owners: []string{"a"}, populations: []int64{0}, wantController: "a"},
+		{name: "population majority", owners: []string{"a", "b"}, populations: []int64{300, 100}, wantController: "a"},
+		{name: "even split is contested", owners: []string{"a", "b"}, populations: []int64{100, 100}, wantContested: true},
+		{name: "no majority is contested", owners: []string{"a", "b", "c"}, populations: []int64{100, 100, 100}, wantContested: true},
+		{name: "lone starbase wins outright", owners: []string{"a", "b"}, populations: []int64{10, 500}, starbases: []int{0}, wantController: "a"},
+		{name: "rival starbases are contested", owners: []string{"a", "b"}, populations: []int64{500, 10}, starbases: []int{0, 1}, wantContested: true},
+	}
+	
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			system := testSystem(test.owners, test.populations)
+			for _, i := range test.starbases {
+				planet := system.Planets[i]
+				system.Structures = append(system.Structures, Structure{Type: "Starbase", Owner: planet.Owner, PlanetID: planet.ID})
+			}
+			
+			controller, contested := systemController(&system)
+			if controller != test.wantController || contested != test.wantContested {
+				t.Errorf("systemController = (%q, %v), want (%q, %v)", controller, contested, test.wantController, test.wantContested)
+			}
+		})
+	}
+}
+
+func TestUpdateSystemControlReportsChanges(t *testing.T) {
+	gs := &GameState{Reports: make(map[string][]string)}
+	gs.Galaxy.AddStarSystem(testSystem([]string{"a", "b"}, []int64{500, 100}))
+	gs.Galaxy.StarSystems[0].ControlledBy = "b"
+	
+	gs.updateSystemControl()
+	
+	if got := gs.Galaxy.StarSystems[0].ControlledBy; got != "a" {
+		t.Fatalf("controlled by %q, want a", got)
+	}
+	if len(gs.Reports["a"]) != 1 || len(gs.Reports["b"]) != 1 {
+		t.Errorf("reports = %v, want one each for the gain and the loss", gs.Reports)
+	}
+	
+	gs.Reports = make(map[string][]string)
+	gs.updateSystemControl()
+	if len(gs.Reports) != 0 {
+		t.Errorf("reports = %v, want none when nothing changed", gs.Reports)
+	}
+}
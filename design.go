@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+type HullType struct {
+	Name       string
+	Slots      int
+	Hull       int
+	Speed      int
+	Fuel       int
+	Cost       Resources
+	Technology string
+}
+
+type Component struct {
+	Name           string
+	Category       string
+	WeaponType     string
+	Attack         int
+	PointDefense   int
+	Shields        int
+	Armor          int
+	Hull           int
+	Speed          int
+	Cargo          int
+	ColonyCapacity int
+	Troops         int
+	Fuel           int
+	Cost           Resources
+	Technology     string
+}
+
+// ShipDesign is a hull fitted with components. Stock designs have no owner
+// and can be built by anyone with the technology for their parts.
+type ShipDesign struct {
+	ID         string
+	Owner      string
+	Name       string
+	Hull       string
+	Components []string
+}
+
+type ShipStats struct {
+	Hull           int
+	Armor          int
+	Shields        int
+	Attack         int
+	Weapons        []Weapon
+	PointDefense   int
+	Speed          int
+	Cargo          int
+	ColonyCapacity int
+	Troops         int
+	Fuel           int
+	Cost           Resources
+}
+
+var hullTypes = map[string]HullType{
+	"Corvette": {
+		Name: "Corvette", Slots: 3, Hull: 40, Speed: 6, Fuel: 4,
+		Cost: Resources{Metals: 30, Energy: 15},
+	},
+	"Frigate": {
+		Name: "Frigate", Slots: 5, Hull: 90, Speed: 4, Fuel: 6,
+		Cost: Resources{Metals: 60, Energy: 30, Minerals: 15},
+	},
+	"Cruiser": {
+		Name: "Cruiser", Slots: 8, Hull: 180, Speed: 3, Fuel: 8,
+		Cost:       Resources{Metals: 120, Energy: 60, Minerals: 40, Technology: 10},
+		Technology: "ImprovedHulls",
+	},
+	"Battleship": {
+		Name: "Battleship", Slots: 12, Hull: 350, Speed: 2, Fuel: 10,
+		Cost:       Resources{Metals: 240, Energy: 120, Minerals: 80, Technology: 20},
+		Technology: "CapitalShips",
+	},
+}
+
+var components = map[string]Component{
+	"MassDriver": {
+		Name: "MassDriver", Category: "weapon", WeaponType: "kinetic", Attack: 10,
+		Cost: Resources{Metals: 15, Energy: 5},
+	},
+	"Laser": {
+		Name: "Laser", Category: "weapon", WeaponType: "energy", Attack: 16,
+		Cost:       Resources{Metals: 10, Energy: 15, Minerals: 5},
+		Technology: "Lasers",
+	},
+	"MissileLauncher": {
+		Name: "MissileLauncher", Category: "weapon", WeaponType: "missile", Attack: 18,
+		Cost:       Resources{Metals: 20, Energy: 10, Minerals: 10},
+		Technology: "Missiles",
+	},
+	"FighterBay": {
+		Name: "FighterBay", Category: "weapon", WeaponType: "fighter", Attack: 14,
+		Cost:       Resources{Metals: 30, Energy: 15, Minerals: 5},
+		Technology: "Carriers",
+	},
+	"PointDefense": {
+		Name: "PointDefense", Category: "point-defense", PointDefense: 3,
+		Cost: Resources{Metals: 15, Energy: 10},
+	},
+	"ShieldGenerator": {
+		Name: "ShieldGenerator", Category: "shield", Shields: 25,
+		Cost: Resources{Energy: 20, Minerals: 10},
+	},
+	"DeflectorShield": {
+		Name: "DeflectorShield", Category: "shield", Shields: 50,
+		Cost:       Resources{Energy: 35, Minerals: 20, Technology: 5},
+		Technology: "DeflectorShields",
+	},
+	"ArmorPlate": {
+		Name: "ArmorPlate", Category: "armor", Armor: 3, Hull: 20,
+		Cost: Resources{Metals: 25, Minerals: 5},
+	},
+	"Engine": {
+		Name: "Engine", Category: "engine", Speed: 2,
+		Cost: Resources{Metals: 10, Energy: 10},
+	},
+	"FuelTank": {
+		Name: "FuelTank", Category: "fuel", Fuel: 4,
+		Cost: Resources{Metals: 15, Energy: 5},
+	},
+	"CargoBay": {
+		Name: "CargoBay", Category: "cargo", Cargo: 50,
+		Cost: Resources{Metals: 15},
+	},
+	"TroopBay": {
+		Name: "TroopBay", Category: "troops", Troops: 20,
+		Cost: Resources{Metals: 30, Energy: 10, Food: 30},
+	},
+	"ColonyPod": {
+		Name: "ColonyPod", Category: "colony", ColonyCapacity: 10000,
+		Cost: Resources{Metals: 40, Energy: 20, Food: 50},
+	},
+}
+
+var stockDesigns = map[string]ShipDesign{
+	"Fighter": {
+		ID: "Fighter", Name: "Fighter", Hull: "Corvette",
+		Components: []string{"MassDriver", "ShieldGenerator", "Engine"},
+	},
+	"Destroyer": {
+		ID: "Destroyer", Name: "Destroyer", Hull: "Frigate",
+		Components: []string{"MassDriver", "MassDriver", "ShieldGenerator", "ArmorPlate", "Engine"},
+	},
+	"Cruiser": {
+		ID: "Cruiser", Name: "Cruiser", Hull: "Cruiser",
+		Components: []string{"MassDriver", "MassDriver", "MassDriver", "ShieldGenerator", "ShieldGenerator",
+			"ArmorPlate", "ArmorPlate", "Engine"},
+	},
+	"Battleship": {
+		ID: "Battleship", Name: "Battleship", Hull: "Battleship",
+		Components: []string{"MassDriver", "MassDriver", "MassDriver", "MassDriver", "MassDriver",
+			"ShieldGenerator", "ShieldGenerator", "ShieldGenerator", "ArmorPlate", "ArmorPlate", "ArmorPlate", "Engine"},
+	},
+	"ColonyShip": {
+		ID: "ColonyShip", Name: "Colony Ship", Hull: "Frigate",
+		Components: []string{"ColonyPod", "Engine"},
+	},
+	"TroopTransport": {
+		ID: "TroopTransport", Name: "Troop Transport", Hull: "Frigate",
+		Components: []string{"TroopBay", "TroopBay", "ArmorPlate", "Engine"},
+	},
+}
+
+// Validate checks that the design's hull and components exist and that the
+// components fit in the hull.
+func (d ShipDesign) Validate() error {
+	hull, exists := hullTypes[d.Hull]
+	if !exists {
+		return fmt.Errorf("unknown hull %q", d.Hull)
+	}
+	if len(d.Components) > hull.Slots {
+		return fmt.Errorf("%s hull has %d slots, design uses %d", d.Hull, hull.Slots, len(d.Components))
+	}
+	for _, name := range d.Components {
+		if _, exists := components[name]; !exists {
+			return fmt.Errorf("unknown component %q", name)
+		}
+	}
+	return nil
+}
+
+// Stats adds up the hull and components of a valid design.
+func (d ShipDesign) Stats() ShipStats {
+	hull := hullTypes[d.Hull]
+	stats := ShipStats{
+		Hull:  hull.Hull,
+		Speed: hull.Speed,
+		Fuel:  hull.Fuel,
+		Cost:  hull.Cost,
+	}
+	
+	for _, name := range d.Components {
+		component := components[name]
+		stats.Hull += component.Hull
+		stats.Armor += component.Armor
+		stats.Shields += component.Shields
+		stats.Attack += component.Attack
+		stats.PointDefense += component.PointDefense
+		if component.WeaponType != "" {
+			stats.Weapons = addWeapon(stats.Weapons, component.WeaponType, component.Attack)
+		}
+		stats.Speed += component.Speed
+		stats.Cargo += component.Cargo
+		stats.ColonyCapacity += component.ColonyCapacity
+		stats.Troops += component.Troops
+		stats.Fuel += component.Fuel
+		stats.Cost.Metals += component.Cost.Metals
+		stats.Cost.Energy += component.Cost.Energy
+		stats.Cost.Minerals += component.Cost.Minerals
+		stats.Cost.Food += component.Cost.Food
+		stats.Cost.Technology += component.Cost.Technology
+	}
+	return stats
+}
+
+// addWeapon adds damage to the ship's weapon of the given type, so that each
+// type fires as one battery.
+func addWeapon(weapons []Weapon, weaponType string, damage int) []Weapon {
+	for i := range weapons {
+		if weapons[i].Type == weaponType {
+			weapons[i].Damage += damage
+			return weapons
+		}
+	}
+	return append(weapons, Weapon{Type: weaponType, Damage: damage})
+}
+
+// requiredTechnologies lists what a player must have researched to build a
+// design.
+func (d ShipDesign) requiredTechnologies() []string {
+	var techs []string
+	if tech := hullTypes[d.Hull].Technology; tech != "" {
+		techs = append(techs, tech)
+	}
+	for _, name := range d.Components {
+		if tech := components[name].Technology; tech != "" {
+			techs = append(techs, tech)
+		}
+	}
+	return techs
+}
+
+func NewSpaceshipFromDesign(id, owner string, design ShipDesign) Spaceship {
+	stats := design.Stats()
+	ship := NewSpaceship(id, design.Name, owner, stats.Hull, stats.Armor, stats.Shields, stats.Attack, stats.Speed)
+	ship.Class = design.Name
+	ship.DesignID = design.ID
+	ship.Cargo = stats.Cargo
+	ship.ColonyCapacity = stats.ColonyCapacity
+	ship.Troops = stats.Troops
+	ship.Weapons = stats.Weapons
+	ship.PointDefense = stats.PointDefense
+	ship.Fuel = stats.Fuel
+	ship.MaxFuel = stats.Fuel
+	return ship
+}
+
+// findDesign looks up a stock design or one of the player's own designs.
+func (gs *GameState) findDesign(designID, playerID string) (ShipDesign, bool) {
+	if design, exists := stockDesigns[designID]; exists {
+		return design, true
+	}
+	design, exists := gs.Designs[designID]
+	if !exists || design.Owner != playerID {
+		return ShipDesign{}, false
+	}
+	return design, true
+}
+
+func (gs *GameState) CanBuildDesign(playerID string, design ShipDesign) bool {
+	for _, tech := range design.requiredTechnologies() {
+		if !gs.HasTechnology(playerID, tech) {
+			return false
+		}
+	}
+	return true
+}
+
+// SaveDesign validates a player's design and stores it under a new ID.
+func (gs *GameState) SaveDesign(playerID, name, hull string, parts []string) (ShipDesign, error) {
+	design := ShipDesign{
+		Owner:      playerID,
+		Name:       name,
+		Hull:       hull,
+		Components: parts,
+	}
+	if design.Name == "" {
+		return ShipDesign{}, fmt.Errorf("design needs a name")
+	}
+	if err := design.Validate(); err != nil {
+		return ShipDesign{}, err
+	}
+	
+	design.ID = gs.newID("design")
+	gs.Designs[design.ID] = design
+	return design, nil
+}
+
+// GetDesigns returns the stock designs and the player's own designs,
+// ordered by ID.
+func (gs *GameState) GetDesigns(playerID string) []ShipDesign {
+	var designs []ShipDesign
+	for _, design := range stockDesigns {
+		designs = append(designs, design)
+	}
+	for _, design := range gs.Designs {
+		if design.Owner == playerID {
+			designs = append(designs, design)
+		}
+	}
+	sort.Slice(designs, func(i, j int) bool {
+		return designs[i].ID < designs[j].ID
+	})
+	return designs
+}
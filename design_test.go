@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDesignStats(t *testing.T) {
+	got := stockDesigns["Destroyer"].Stats()
+	want := ShipStats{
+		Hull:    110,
+		Armor:   3,
+		Shields: 25,
+		Attack:  20,
+		Speed:   6,
+		Fuel:    6,
+		Cost:    Resources{Metals: 125, Energy: 70, Minerals: 30},
+		Weapons: []Weapon{{Type: "kinetic", Damage: 20}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Destroyer stats = %+v, want %+v", got, want)
+	}
+	
+	ship := NewSpaceshipFromDesign("ship_1", "a", stockDesigns["ColonyShip"])
+	if ship.ColonyCapacity != 10000 || ship.DesignID != "ColonyShip" || ship.Hull != ship.MaxHull {
+		t.Errorf("colony ship = %+v", ship)
+	}
+}
+
+func TestSaveDesign(t *testing.T) {
+	tests := []struct {
+		name    string
+		design  string
+		hull    string
+		parts   []string
+		wantErr string
+	}{
+		{name: "valid", design: "Raider", hull: "Corvette", parts: []string{"Laser", "Engine", "Engine"}},
+		{name: "empty hull", design: "Hulk", hull: "Frigate"},
+		{name: "no name", hull: "Corvette", wantErr: "name"},
+		{name: "unknown hull", design: "Ark", hull: "Dreadnought", wantErr: "unknown hull"},
+		{name: "unknown component", design: "Ark", hull: "Corvette", parts: []string{"Phaser"}, wantErr: "unknown component"},
+		{name: "too many parts", design: "Brick", hull: "Corvette", parts: []string{"ArmorPlate", "ArmorPlate", "ArmorPlate", "ArmorPlate"}, wantErr: "slots"},
+	}
+	
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gs := &GameState{Designs: make(map[string]ShipDesign)}
+			design, err := gs.SaveDesign("a", test.design, test.hull, test.parts)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("error = %v, want one about %q", err, test.wantErr)
+				}
+				if len(gs.Designs) != 0 {
+					t.Errorf("invalid design was stored")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stored, exists := gs.findDesign(design.ID, "a"); !exists || stored.Name != test.design {
+				t.Errorf("saved design not found under %s", design.ID)
+			}
+		})
+	}
+}
+
+func TestFindDesignIsPrivate(t *testing.T) {
+	gs := &GameState{Designs: make(map[string]ShipDesign)}
+	design, err := gs.SaveDesign("a", "Raider", "Corvette", []string{"Laser"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	if _, exists := gs.findDesign(design.ID, "b"); exists {
+		t.Errorf("another player found a's design")
+	}
+	if _, exists := gs.findDesign("Fighter", "b"); !exists {
+		t.Errorf("stock design not found")
+	}
+	if len(gs.GetDesigns("b")) != len(stockDesigns) {
+		t.Errorf("b sees %d designs, want only the stock ones", len(gs.GetDesigns("b")))
+	}
+}
+
+func TestCanBuildDesignNeedsTechnology(t *testing.T) {
+	gs := &GameState{Research: map[string]map[string]bool{"a": {}}}
+	design := ShipDesign{Hull: "Cruiser", Components: []string{"Laser"}}
+	
+	if gs.CanBuildDesign("a", design) {
+		t.Errorf("built without ImprovedHulls or Lasers")
+	}
+	gs.Research["a"]["ImprovedHulls"] = true
+	if gs.CanBuildDesign("a", design) {
+		t.Errorf("built without Lasers")
+	}
+	gs.Research["a"]["Lasers"] = true
+	if !gs.CanBuildDesign("a", design) {
+		t.Errorf("can't build with every technology researched")
+	}
+}
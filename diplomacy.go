@@ -0,0 +1,123 @@
+package main
+
+import "fmt"
+
+const (
+	StatusWar      = "war"
+	StatusNeutral  = "neutral"
+	StatusPeace    = "peace"
+	StatusAlliance = "alliance"
+)
+
+// statusRank orders statuses from most to least hostile.
+var statusRank = map[string]int{
+	StatusWar:      0,
+	StatusNeutral:  1,
+	StatusPeace:    2,
+	StatusAlliance: 3,
+}
+
+func relationKey(playerA, playerB string) string {
+	if playerA > playerB {
+		playerA, playerB = playerB, playerA
+	}
+	return playerA + "|" + playerB
+}
+
+// Relation returns the diplomatic status between two players. Players who
+// have never agreed anything are at war.
+func (gs *GameState) Relation(playerA, playerB string) string {
+	if playerA == playerB {
+		return StatusAlliance
+	}
+	if status, exists := gs.Relations[relationKey(playerA, playerB)]; exists {
+		return status
+	}
+	return StatusWar
+}
+
+func (gs *GameState) setRelation(playerA, playerB, status string) {
+	gs.Relations[relationKey(playerA, playerB)] = status
+	delete(gs.Proposals, relationKey(playerA, playerB))
+}
+
+// areHostile reports whether two players are at war.
+func (gs *GameState) areHostile(playerA, playerB string) bool {
+	return gs.Relation(playerA, playerB) == StatusWar
+}
+
+func (gs *GameState) areAllied(playerA, playerB string) bool {
+	return gs.Relation(playerA, playerB) == StatusAlliance
+}
+
+// processDiplomacyOrder changes the player's stance toward another player.
+// Relations can be worsened at will, but improving them takes a proposal
+// from each side: the change happens once both have asked for it.
+func (gs *GameState) processDiplomacyOrder(order Order) {
+	target, _ := order.Parameters["target"].(string)
+	status, _ := order.Parameters["status"].(string)
+	if gs.findPlayer(target) == nil || target == order.PlayerID {
+		return
+	}
+	if _, valid := statusRank[status]; !valid {
+		return
+	}
+	
+	current := gs.Relation(order.PlayerID, target)
+	if status == current {
+		return
+	}
+	
+	if statusRank[status] < statusRank[current] {
+		gs.setRelation(order.PlayerID, target, status)
+		fmt.Printf("Player %s changed relations with %s to %s\n", order.PlayerID, target, status)
+		gs.report(order.PlayerID, "Relations with %s are now %s", target, status)
+		gs.report(target, "%s changed relations with you to %s", order.PlayerID, status)
+		return
+	}
+	
+	key := relationKey(order.PlayerID, target)
+	if gs.Proposals[key] == nil {
+		gs.Proposals[key] = make(map[string]string)
+	}
+	gs.Proposals[key][order.PlayerID] = status
+	
+	if gs.Proposals[key][target] == status {
+		gs.setRelation(order.PlayerID, target, status)
+		fmt.Printf("Players %s and %s agreed to %s\n", order.PlayerID, target, status)
+		gs.report(order.PlayerID, "%s accepted %s", target, status)
+		gs.report(target, "%s accepted %s", order.PlayerID, status)
+		return
+	}
+	gs.report(target, "%s proposes %s", order.PlayerID, status)
+}
+
+// actOfWar puts the aggressor at war with the victim and with everyone
+// allied to the victim.
+func (gs *GameState) actOfWar(aggressor, victim, reason string) {
+	for _, player := range gs.Players {
+		if player.ID == aggressor {
+			continue
+		}
+		if player.ID != victim && !gs.areAllied(player.ID, victim) {
+			continue
+		}
+		if gs.areHostile(aggressor, player.ID) {
+			continue
+		}
+		
+		gs.setRelation(aggressor, player.ID, StatusWar)
+		gs.report(aggressor, "Now at war with %s after %s", player.ID, reason)
+		gs.report(player.ID, "Now at war with %s after %s", aggressor, reason)
+	}
+}
+
+func (gs *GameState) GetRelations(playerID string) map[string]string {
+	relations := make(map[string]string)
+	for _, player := range gs.Players {
+		if player.ID != playerID {
+			relations[player.ID] = gs.Relation(playerID, player.ID)
+		}
+	}
+	return relations
+}
@@ -0,0 +1,82 @@
+package main
+
+import "testing"
+
+func newDiplomacyTestGame() *GameState {
+	return &GameState{
+		Players:   []Player{{ID: "a"}, {ID: "b"}, {ID: "c"}},
+		Relations: make(map[string]string),
+		Proposals: make(map[string]map[string]string),
+		Reports:   make(map[string][]string),
+	}
+}
+
+func diplomacyOrder(player, target, status string) Order {
+	return Order{
+		PlayerID:   player,
+		OrderType:  string(OrderDiplomacy),
+		Parameters: map[string]interface{}{"target": target, "status": status},
+	}
+}
+
+func TestImprovingRelationsNeedsBothSides(t *testing.T) {
+	gs := newDiplomacyTestGame()
+	
+	gs.processDiplomacyOrder(diplomacyOrder("a", "b", StatusPeace))
+	if got := gs.Relation("a", "b"); got != StatusWar {
+		t.Fatalf("relation after one proposal = %s, want war", got)
+	}
+	
+	// A different offer from the other side is not an agreement
+	gs.processDiplomacyOrder(diplomacyOrder("b", "a", StatusNeutral))
+	if got := gs.Relation("a", "b"); got != StatusWar {
+		t.Fatalf("relation after mismatched proposals = %s, want war", got)
+	}
+	
+	gs.processDiplomacyOrder(diplomacyOrder("b", "a", StatusPeace))
+	if got := gs.Relation("b", "a"); got != StatusPeace {
+		t.Fatalf("relation after both proposed peace = %s, want peace", got)
+	}
+	if len(gs.Proposals[relationKey("a", "b")]) != 0 {
+		t.Errorf("proposals left over after agreement: %v", gs.Proposals)
+	}
+	
+	// Going back to war needs only one side
+	gs.processDiplomacyOrder(diplomacyOrder("a", "b", StatusWar))
+	if got := gs.Relation("a", "b"); got != StatusWar {
+		t.Errorf("relation after declaring war = %s, want war", got)
+	}
+}
+
+func TestInvalidDiplomacyOrders(t *testing.T) {
+	for _, order := range []Order{
+		diplomacyOrder("a", "a", StatusAlliance),
+		diplomacyOrder("a", "nobody", StatusAlliance),
+		diplomacyOrder("a", "b", "vassal"),
+	} {
+		gs := newDiplomacyTestGame()
+		gs.processDiplomacyOrder(order)
+		if len(gs.Relations) != 0 || len(gs.Proposals) != 0 {
+			t.Errorf("order %v changed relations %v, proposals %v", order.Parameters, gs.Relations, gs.Proposals)
+		}
+	}
+}
+
+func TestActOfWarBringsInAllies(t *testing.T) {
+	gs := newDiplomacyTestGame()
+	gs.setRelation("a", "b", StatusPeace)
+	gs.setRelation("a", "c", StatusPeace)
+	gs.setRelation("b", "c", StatusAlliance)
+	
+	gs.actOfWar("a", "b", "a test")
+	
+	want := map[string]string{"b": StatusWar, "c": StatusWar}
+	for player, status := range gs.GetRelations("a") {
+		if status != want[player] {
+			t.Errorf("a and %s are at %s, want %s", player, status, want[player])
+		}
+	}
+	if gs.Relation("b", "c") != StatusAlliance {
+		t.Errorf("the alliance between b and c was broken")
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// addShipToSystem puts a newly built ship into one of its owner's fleets in
+// the system, creating a fleet if the owner has none there.
+func (gs *GameState) addShipToSystem(ship Spaceship, systemID string) *Fleet {
+	for i := range gs.Fleets {
+		fleet := &gs.Fleets[i]
+		if fleet.Owner == ship.Owner && fleet.Location == systemID && !fleet.InTransit() {
+			fleet.AddShip(ship)
+			return fleet
+		}
+	}
+	
+	gs.Fleets = append(gs.Fleets, NewFleet(gs.newID("fleet"), ship.Owner, systemID, []Spaceship{ship}))
+	return &gs.Fleets[len(gs.Fleets)-1]
+}
+
+func (gs *GameState) findFleet(fleetID string) *Fleet {
+	for i := range gs.Fleets {
+		if gs.Fleets[i].ID == fleetID {
+			return &gs.Fleets[i]
+		}
+	}
+	return nil
+}
+
+func (gs *GameState) GetFleetsByOwner(owner string) []Fleet {
+	var fleets []Fleet
+	for _, fleet := range gs.Fleets {
+		if fleet.Owner == owner {
+			fleets = append(fleets, fleet)
+		}
+	}
+	return fleets
+}
+
+// GetFleetsInSystem returns the fleets present in a system, leaving out
+// fleets that have departed from it.
+func (gs *GameState) GetFleetsInSystem(systemID string) []Fleet {
+	var fleets []Fleet
+	for _, fleet := range gs.Fleets {
+		if fleet.Location == systemID && !fleet.InTransit() {
+			fleets = append(fleets, fleet)
+		}
+	}
+	return fleets
+}
+
+// travelTurns is how many turns a fleet needs to cross between two systems.
+func travelTurns(fleet *Fleet, from, to *StarSystem) int {
+	speed := fleet.Speed()
+	if speed <= 0 {
+		return 0
+	}
+	distance := CalculateDistance(from.Coordinates, to.Coordinates)
+	return max(1, int(math.Ceil(distance/float64(speed))))
+}
+
+// processMoveFleetOrder sends a fleet along a planned route. The order names
+// a destination system, a list of waypoints, or both, in which case the
+// destination comes last. With "patrol" the fleet loops through the
+// waypoints and back to where it started until given another order.
+func (gs *GameState) processMoveFleetOrder(order Order) {
+	fleetID, _ := order.Parameters["fleet_id"].(string)
+	fleet := gs.findFleet(fleetID)
+	if fleet == nil || fleet.Owner != order.PlayerID || fleet.IsDefeated() {
+		return
+	}
+	
+	waypoints := stringList(order.Parameters["waypoints"])
+	
+	destinationID := order.SystemID
+	if destinationID == "" {
+		destinationID, _ = order.Parameters["destination"].(string)
+	}
+	if destinationID != "" {
+		waypoints = append(waypoints, destinationID)
+	}
+	if len(waypoints) == 0 {
+		return
+	}
+	
+	avoidHostile, _ := order.Parameters["avoid_hostile"].(bool)
+	patrol, _ := order.Parameters["patrol"].(bool)
+	
+	start := fleet.Location
+	if fleet.InTransit() {
+		start = fleet.Destination
+	}
+	
+	var loop []string
+	if patrol {
+		loop = append([]string{start}, waypoints...)
+		waypoints = patrolLeg(loop)
+	}
+	
+	path := gs.planRoute(fleet, waypoints, avoidHostile)
+	if len(path) < 2 {
+		return
+	}
+	
+	fleet.Route = path[1:]
+	fleet.AvoidHostile = avoidHostile
+	fleet.BombardTarget = ""
+	fleet.Patrol = loop
+	fmt.Printf("Player %s fleet %s routed to %s via %d systems (ETA %d turns)\n",
+		order.PlayerID, fleet.ID, path[len(path)-1], len(path)-1, gs.routeETA(fleet, path))
+}
+
+// patrolLeg lists the waypoints for one lap of a patrol loop, which ends
+// back where it started.
+func patrolLeg(loop []string) []string {
+	leg := append([]string{}, loop[1:]...)
+	return append(leg, loop[0])
+}
+
+// advanceFleets moves every fleet with somewhere to go one turn along its
+// route. Fleets stop in each system they reach at the end of the turn and
+// set out for the next one on the following turn. Arrivals are reported to
+// anyone with planets in the system, and to the fleet's owner when the
+// fleet reaches the end of its route.
+func (gs *GameState) advanceFleets() {
+	for i := range gs.Fleets {
+		fleet := &gs.Fleets[i]
+		
+		if !fleet.InTransit() && len(fleet.Route) > 0 {
+			from := gs.Galaxy.GetSystemByID(fleet.Location)
+			to := gs.Galaxy.GetSystemByID(fleet.Route[0])
+			if from == nil || to == nil {
+				fleet.Route = nil
+				continue
+			}
+			if fleet.Fuel() == 0 && !gs.SupplyNetwork(fleet.Owner)[to.ID] {
+				gs.report(fleet.Owner, "Fleet %s has no fuel to leave %s for %s", fleet.ID, from.Name, to.Name)
+				continue
+			}
+			fleet.Destination = to.ID
+			fleet.TurnsRemaining = travelTurns(fleet, from, to)
+			fleet.Route = fleet.Route[1:]
+		}
+		
+		if !fleet.InTransit() {
+			continue
+		}
+		
+		fleet.TurnsRemaining--
+		if fleet.TurnsRemaining > 0 {
+			continue
+		}
+		
+		fleet.Location = fleet.Destination
+		fleet.Destination = ""
+		fleet.TurnsRemaining = 0
+		
+		system := gs.Galaxy.GetSystemByID(fleet.Location)
+		fmt.Printf("Player %s fleet %s arrived at %s\n", fleet.Owner, fleet.ID, system.Name)
+		for owner := range gs.systemPlanetOwners(system) {
+			if owner != fleet.Owner {
+				gs.report(owner, "A fleet of %s arrived at %s", fleet.Owner, system.Name)
+			}
+		}
+		
+		if len(fleet.Route) > 0 {
+			continue
+		}
+		if len(fleet.Patrol) > 0 {
+			if path := gs.planRoute(fleet, patrolLeg(fleet.Patrol), fleet.AvoidHostile); len(path) > 1 {
+				fleet.Route = path[1:]
+				continue
+			}
+			fleet.Patrol = nil
+			gs.report(fleet.Owner, "Fleet %s can't continue its patrol from %s", fleet.ID, system.Name)
+			continue
+		}
+		gs.report(fleet.Owner, "Fleet %s arrived at %s", fleet.ID, system.Name)
+	}
+}
+
+// hasHostileFleet reports whether any fleet hostile to the player is in the
+// system.
+func (gs *GameState) hasHostileFleet(systemID, playerID string) bool {
+	for _, fleet := range gs.GetFleetsInSystem(systemID) {
+		if gs.areHostile(fleet.Owner, playerID) && !fleet.IsDefeated() {
+			return true
+		}
+	}
+	return false
+}
+
+func (gs *GameState) removeFleet(fleetID string) {
+	for i := range gs.Fleets {
+		if gs.Fleets[i].ID == fleetID {
+			gs.Fleets = append(gs.Fleets[:i], gs.Fleets[i+1:]...)
+			return
+		}
+	}
+}
+
+// findIdleFleet finds a fleet that belongs to the player and is sitting in a
+// system rather than travelling, so it can be reorganised.
+func (gs *GameState) findIdleFleet(fleetID, playerID string) *Fleet {
+	fleet := gs.findFleet(fleetID)
+	if fleet == nil || fleet.Owner != playerID || fleet.InTransit() {
+		return nil
+	}
+	return fleet
+}
+
+func (gs *GameState) processFleetOrganisationOrders() {
+	fmt.Println("Processing fleet organisation orders...")
+	
+	for _, orders := range gs.Orders {
+		for _, order := range orders {
+			switch OrderType(order.OrderType) {
+			case OrderMergeFleet:
+				gs.processMergeFleetOrder(order)
+			case OrderSplitFleet:
+				gs.processSplitFleetOrder(order)
+			case OrderTransferShips:
+				gs.processTransferShipsOrder(order)
+			case OrderRenameFleet:
+				gs.processRenameFleetOrder(order)
+			case OrderSetDoctrine:
+				gs.processDoctrineOrder(order)
+			case OrderSetRange:
+				gs.processRangeOrder(order)
+			case OrderSetRetreat:
+				gs.processRetreatOrder(order)
+			}
+		}
+	}
+}
+
+// processMergeFleetOrder moves every ship of one fleet into another in the
+// same system and disbands the emptied fleet.
+func (gs *GameState) processMergeFleetOrder(order Order) {
+	targetID, _ := order.Parameters["fleet_id"].(string)
+	sourceID, _ := order.Parameters["merge_fleet_id"].(string)
+	if targetID == sourceID {
+		return
+	}
+	
+	target := gs.findIdleFleet(targetID, order.PlayerID)
+	source := gs.findIdleFleet(sourceID, order.PlayerID)
+	if target == nil || source == nil || target.Location != source.Location {
+		return
+	}
+	
+	target.Ships = append(target.Ships, source.Ships...)
+	gs.removeFleet(sourceID)
+	fmt.Printf("Player %s merged fleet %s into %s\n", order.PlayerID, sourceID, targetID)
+}
+
+// processSplitFleetOrder detaches some of a fleet's ships into a new fleet
+// in the same system. At least one ship has to stay behind.
+func (gs *GameState) processSplitFleetOrder(order Order) {
+	fleetID, _ := order.Parameters["fleet_id"].(string)
+	fleet := gs.findIdleFleet(fleetID, order.PlayerID)
+	if fleet == nil {
+		return
+	}
+	
+	shipIDs := stringList(order.Parameters["ship_ids"])
+	if len(shipIDs) == 0 || len(shipIDs) >= len(fleet.Ships) {
+		return
+	}
+	
+	ships := fleet.RemoveShips(shipIDs)
+	if ships == nil {
+		return
+	}
+	
+	newFleet := NewFleet(gs.newID("fleet"), order.PlayerID, fleet.Location, ships)
+	newFleet.Morale = fleet.Morale
+	if name, ok := order.Parameters["name"].(string); ok && name != "" {
+		newFleet.Name = name
+	}
+	gs.Fleets = append(gs.Fleets, newFleet)
+	fmt.Printf("Player %s split %d ships from fleet %s into %s\n", order.PlayerID, len(ships), fleetID, newFleet.ID)
+}
+
+// processTransferShipsOrder moves some ships between two fleets in the same
+// system. A fleet left without ships is disbanded.
+func (gs *GameState) processTransferShipsOrder(order Order) {
+	sourceID, _ := order.Parameters["fleet_id"].(string)
+	targetID, _ := order.Parameters["target_fleet_id"].(string)
+	if sourceID == targetID {
+		return
+	}
+	
+	source := gs.findIdleFleet(sourceID, order.PlayerID)
+	target := gs.findIdleFleet(targetID, order.PlayerID)
+	if source == nil || target == nil || source.Location != target.Location {
+		return
+	}
+	
+	ships := source.RemoveShips(stringList(order.Parameters["ship_ids"]))
+	if ships == nil {
+		return
+	}
+	
+	target.Ships = append(target.Ships, ships...)
+	if len(source.Ships) == 0 {
+		gs.removeFleet(sourceID)
+	}
+	fmt.Printf("Player %s transferred %d ships from fleet %s to %s\n", order.PlayerID, len(ships), sourceID, targetID)
+}
+
+func (gs *GameState) processRenameFleetOrder(order Order) {
+	fleetID, _ := order.Parameters["fleet_id"].(string)
+	fleet := gs.findFleet(fleetID)
+	name, _ := order.Parameters["name"].(string)
+	if fleet == nil || fleet.Owner != order.PlayerID || name == "" {
+		return
+	}
+	
+	fleet.Name = name
+}
+
+// stringList reads a JSON array of strings from an order parameter.
+func stringList(value interface{}) []string {
+	list, _ := value.([]interface{})
+	result := []string{}
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
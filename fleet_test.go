@@ -0,0 +1,166 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTravelTurns(t *testing.T) {
+	from := NewStarSystem("from", "From", Star{}, Coordinates{})
+	tests := []struct {
+		distance float64
+		speed    int
+		want     int
+	}{
+		{distance: 30, speed: 10, want: 3},
+		{distance: 31, speed: 10, want: 4},
+		{distance: 5, speed: 10, want: 1},
+		{distance: 30, speed: 0, want: 0},
+	}
+	
+	for _, test := range tests {
+		to := NewStarSystem("to", "To", Star{}, Coordinates{X: test.distance})
+		fleet := NewFleet("fleet_1", "a", from.ID, []Spaceship{NewSpaceship("ship_1", "Ship", "a", 10, 0, 0, 0, test.speed)})
+		if got := travelTurns(&fleet, &from, &to); got != test.want {
+			t.Errorf("travelTurns(%v at speed %d) = %d, want %d", test.distance, test.speed, got, test.want)
+		}
+	}
+}
+
+func TestMoveFleetArrivesAfterTravel(t *testing.T) {
+	gs := &GameState{Reports: make(map[string][]string)}
+	gs.Galaxy = newTestGalaxy(map[string]Coordinates{
+		"home": {},
+		"away": {X: 25},
+	}, [][2]string{{"home", "away"}})
+	ship := NewSpaceship("ship_1", "Ship", "a", 10, 0, 0, 0, 10)
+	ship.Fuel = 5
+	ship.MaxFuel = 5
+	gs.Fleets = []Fleet{NewFleet("fleet_1", "a", "home", []Spaceship{ship})}
+	
+	// Only the owner can move the fleet
+	gs.processMoveFleetOrder(Order{PlayerID: "b", SystemID: "away", Parameters: map[string]interface{}{"fleet_id": "fleet_1"}})
+	if len(gs.Fleets[0].Route) != 0 {
+		t.Fatalf("fleet routed on another player's order")
+	}
+	
+	gs.processMoveFleetOrder(Order{PlayerID: "a", SystemID: "away", Parameters: map[string]interface{}{"fleet_id": "fleet_1"}})
+	fleet := &gs.Fleets[0]
+	if len(fleet.Route) != 1 || fleet.Route[0] != "away" {
+		t.Fatalf("route = %v, want [away]", fleet.Route)
+	}
+	
+	for turn := 1; turn <= 3; turn++ {
+		gs.advanceFleets()
+		if turn == 1 && len(gs.GetFleetsInSystem("home")) != 0 {
+			t.Errorf("departed fleet still counted in its old system")
+		}
+		if arrived := !fleet.InTransit(); arrived != (turn == 3) {
+			t.Fatalf("after %d turns arrived = %v", turn, arrived)
+		}
+	}
+	if fleet.Location != "away" {
+		t.Errorf("location = %q, want away", fleet.Location)
+	}
+	if len(gs.Reports["a"]) != 1 {
+		t.Errorf("reports = %v, want the arrival", gs.Reports["a"])
+	}
+}
+
+func newOrganisationTestGame() *GameState {
+	ship := func(id, owner string) Spaceship {
+		return NewSpaceship(id, "Ship", owner, 10, 0, 0, 0, 10)
+	}
+	gs := &GameState{}
+	gs.Fleets = []Fleet{
+		NewFleet("alpha", "a", "home", []Spaceship{ship("s1", "a"), ship("s2", "a"), ship("s3", "a")}),
+		NewFleet("beta", "a", "home", []Spaceship{ship("s4", "a")}),
+		NewFleet("gamma", "a", "away", []Spaceship{ship("s5", "a")}),
+		NewFleet("delta", "b", "home", []Spaceship{ship("s6", "b")}),
+	}
+	return gs
+}
+
+func TestFleetOrganisationOrders(t *testing.T) {
+	tests := []struct {
+		name      string
+		orderType OrderType
+		player    string
+		params    map[string]interface{}
+		want      map[string]int
+	}{
+		{
+			name: "merge", orderType: OrderMergeFleet, player: "a",
+			params: map[string]interface{}{"fleet_id": "alpha", "merge_fleet_id": "beta"},
+			want:   map[string]int{"alpha": 4, "gamma": 1, "delta": 1},
+		},
+		{
+			name: "merge across systems", orderType: OrderMergeFleet, player: "a",
+			params: map[string]interface{}{"fleet_id": "alpha", "merge_fleet_id": "gamma"},
+			want:   map[string]int{"alpha": 3, "beta": 1, "gamma": 1, "delta": 1},
+		},
+		{
+			name: "merge another player's fleet", orderType: OrderMergeFleet, player: "a",
+			params: map[string]interface{}{"fleet_id": "alpha", "merge_fleet_id": "delta"},
+			want:   map[string]int{"alpha": 3, "beta": 1, "gamma": 1, "delta": 1},
+		},
+		{
+			name: "split", orderType: OrderSplitFleet, player: "a",
+			params: map[string]interface{}{"fleet_id": "alpha", "ship_ids": []interface{}{"s1", "s2"}},
+			want:   map[string]int{"alpha": 1, "beta": 1, "gamma": 1, "delta": 1, "fleet_1": 2},
+		},
+		{
+			name: "split off every ship", orderType: OrderSplitFleet, player: "a",
+			params: map[string]interface{}{"fleet_id": "beta", "ship_ids": []interface{}{"s4"}},
+			want:   map[string]int{"alpha": 3, "beta": 1, "gamma": 1, "delta": 1},
+		},
+		{
+			name: "split a ship from another fleet", orderType: OrderSplitFleet, player: "a",
+			params: map[string]interface{}{"fleet_id": "alpha", "ship_ids": []interface{}{"s4"}},
+			want:   map[string]int{"alpha": 3, "beta": 1, "gamma": 1, "delta": 1},
+		},
+		{
+			name: "transfer", orderType: OrderTransferShips, player: "a",
+			params: map[string]interface{}{"fleet_id": "alpha", "target_fleet_id": "beta", "ship_ids": []interface{}{"s3"}},
+			want:   map[string]int{"alpha": 2, "beta": 2, "gamma": 1, "delta": 1},
+		},
+		{
+			name: "transfer the last ship disbands the fleet", orderType: OrderTransferShips, player: "a",
+			params: map[string]interface{}{"fleet_id": "beta", "target_fleet_id": "alpha", "ship_ids": []interface{}{"s4"}},
+			want:   map[string]int{"alpha": 4, "gamma": 1, "delta": 1},
+		},
+	}
+	
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gs := newOrganisationTestGame()
+			gs.Orders = map[string][]Order{
+				test.player: {{PlayerID: test.player, OrderType: string(test.orderType), Parameters: test.params}},
+			}
+			
+			gs.processFleetOrganisationOrders()
+			
+			got := make(map[string]int)
+			for _, fleet := range gs.Fleets {
+				got[fleet.ID] = len(fleet.Ships)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ships per fleet = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenameFleet(t *testing.T) {
+	gs := newOrganisationTestGame()
+	gs.processRenameFleetOrder(Order{PlayerID: "b", Parameters: map[string]interface{}{"fleet_id": "alpha", "name": "Stolen"}})
+	gs.processRenameFleetOrder(Order{PlayerID: "a", Parameters: map[string]interface{}{"fleet_id": "alpha", "name": ""}})
+	if name := gs.findFleet("alpha").Name; name != "alpha" {
+		t.Fatalf("name = %q after invalid renames, want alpha", name)
+	}
+	
+	gs.processRenameFleetOrder(Order{PlayerID: "a", Parameters: map[string]interface{}{"fleet_id": "alpha", "name": "Home Guard"}})
+	if name := gs.findFleet("alpha").Name; name != "Home Guard" {
+		t.Errorf("name = %q, want Home Guard", name)
+	}
+}
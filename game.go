@@ -6,13 +6,25 @@ import (
 )
 
 type GameState struct {
-	Galaxy      Galaxy
-	Players     []Player
-	CurrentTurn int
-	MaxTurns    int
-	Orders      map[string][]Order
-	GameOver    bool
-	Winner      string
+	Galaxy            Galaxy
+	Players           []Player
+	CurrentTurn       int
+	MaxTurns          int
+	Orders            map[string][]Order
+	GameOver          bool
+	Winner            string
+	Market            Market
+	TradeRoutes       []TradeRoute
+	NextID            int
+	Research          map[string]map[string]bool
+	TerraformProjects []TerraformProject
+	Reports           map[string][]string
+	Fleets            []Fleet
+	Designs           map[string]ShipDesign
+	Rules             GameRules
+	Relations         map[string]string
+	Proposals         map[string]map[string]string
+	Battles           []BattleRecord
 }
 
 type Order struct {
@@ -33,6 +45,22 @@ const (
 	OrderMoveFleet        OrderType = "MOVE_FLEET"
 	OrderColonizePlanet   OrderType = "COLONIZE_PLANET"
 	OrderResearch         OrderType = "RESEARCH"
+	OrderMarketBuy        OrderType = "MARKET_BUY"
+	OrderMarketSell       OrderType = "MARKET_SELL"
+	OrderTradeRoute       OrderType = "CREATE_TRADE_ROUTE"
+	OrderCancelTradeRoute OrderType = "CANCEL_TRADE_ROUTE"
+	OrderTerraform        OrderType = "TERRAFORM"
+	OrderMergeFleet       OrderType = "MERGE_FLEET"
+	OrderSplitFleet       OrderType = "SPLIT_FLEET"
+	OrderTransferShips    OrderType = "TRANSFER_SHIPS"
+	OrderRenameFleet      OrderType = "RENAME_FLEET"
+	OrderInvadePlanet     OrderType = "INVADE_PLANET"
+	OrderBombard          OrderType = "BOMBARD"
+	OrderDiplomacy        OrderType = "DIPLOMACY"
+	OrderSetDoctrine      OrderType = "SET_DOCTRINE"
+	OrderSetRange         OrderType = "SET_RANGE"
+	OrderSetRetreat       OrderType = "SET_RETREAT"
+	OrderBuildStructure   OrderType = "BUILD_STRUCTURE"
 )
 
 func NewGameState(players []Player, galaxySize int, maxTurns int) GameState {
@@ -46,6 +74,16 @@ func NewGameState(players []Player, galaxySize int, maxTurns int) GameState {
 		Orders:      make(map[string][]Order),
 		GameOver:    false,
 		Winner:      "",
+		Market:      NewMarket(),
+		TradeRoutes: []TradeRoute{},
+		Research:    make(map[string]map[string]bool),
+		Reports:     make(map[string][]string),
+		Fleets:      []Fleet{},
+		Designs:     make(map[string]ShipDesign),
+		Rules:       DefaultGameRules(),
+		Relations:   make(map[string]string),
+		Proposals:   make(map[string]map[string]string),
+		Battles:     []BattleRecord{},
 	}
 }
 
@@ -58,6 +96,7 @@ func (gs *GameState) AddOrder(order Order) {
 
 func (gs *GameState) ProcessTurn() {
 	fmt.Printf("\n=== Processing Turn %d ===\n", gs.CurrentTurn)
+	gs.Reports = make(map[string][]string)
 	
 	// Sort orders by priority
 	allOrders := []Order{}
@@ -71,15 +110,50 @@ func (gs *GameState) ProcessTurn() {
 	// Process production orders first
 	gs.processProductionOrders()
 	
+	// Settle diplomacy before fleets act
+	gs.processDiplomacyOrders()
+	
+	// Reorganise fleets before they move
+	gs.processFleetOrganisationOrders()
+	
 	// Process movement orders
 	gs.processMovementOrders()
 	
+	// Fight wherever hostile fleets meet
+	gs.resolveCombat()
+	
 	// Process construction orders
 	gs.processConstructionOrders()
 	
+	// Bombard planets from orbit
+	gs.updateBombardment()
+	
+	// Advance terraforming projects
+	gs.updateTerraforming()
+	
+	// Process trade orders
+	gs.processTradeOrders()
+	
 	// Update resources
 	gs.updateResources()
 	
+	// Recompute system control from planet ownership
+	gs.updateSystemControl()
+	
+	// Refuel fleets in supply and wear down the rest
+	gs.updateSupply()
+	
+	// Recharge shields and repair fleets in friendly systems
+	gs.updateRepairs()
+	gs.updateStructures()
+	
+	// Let fleets in supply recover morale
+	gs.updateMorale()
+	
+	// Settle the market and collect trade income
+	gs.updateMarket()
+	gs.updateTradeRoutes()
+	
 	// Clear orders for next turn
 	gs.Orders = make(map[string][]Order)
 	
@@ -105,8 +179,12 @@ func (gs *GameState) processProductionOrders() {
 				gs.processBuildShipOrder(order)
 			case OrderBuildFacility:
 				gs.processBuildFacilityOrder(order)
+			case OrderBuildStructure:
+				gs.processBuildStructureOrder(order)
 			case OrderUpgradeFacility:
 				gs.processUpgradeFacilityOrder(order)
+			case OrderResearch:
+				gs.processResearchOrder(order)
 			}
 		}
 		_ = playerID
@@ -123,6 +201,8 @@ func (gs *GameState) processMovementOrders() {
 			}
 		}
 	}
+	
+	gs.advanceFleets()
 }
 
 func (gs *GameState) processConstructionOrders() {
@@ -130,31 +210,77 @@ func (gs *GameState) processConstructionOrders() {
 	
 	for _, orders := range gs.Orders {
 		for _, order := range orders {
-			if OrderType(order.OrderType) == OrderColonizePlanet {
+			switch OrderType(order.OrderType) {
+			case OrderColonizePlanet:
 				gs.processColonizeOrder(order)
+			case OrderTerraform:
+				gs.processTerraformOrder(order)
+			case OrderInvadePlanet:
+				gs.processInvadeOrder(order)
+			case OrderBombard:
+				gs.processBombardOrder(order)
+			}
+		}
+	}
+}
+
+func (gs *GameState) processTradeOrders() {
+	fmt.Println("Processing trade orders...")
+	
+	for _, orders := range gs.Orders {
+		for _, order := range orders {
+			switch OrderType(order.OrderType) {
+			case OrderMarketBuy, OrderMarketSell:
+				gs.processMarketOrder(order)
+			case OrderTradeRoute, OrderCancelTradeRoute:
+				gs.processTradeRouteOrder(order)
 			}
 		}
 	}
 }
 
+// processDiplomacyOrders applies every player's diplomacy orders before
+// fleets move, so changes of stance take effect in this turn's battles.
+func (gs *GameState) processDiplomacyOrders() {
+	fmt.Println("Processing diplomacy orders...")
+	
+	for _, orders := range gs.Orders {
+		for _, order := range orders {
+			if OrderType(order.OrderType) == OrderDiplomacy {
+				gs.processDiplomacyOrder(order)
+			}
+		}
+	}
+}
+
+// processBuildShipOrder builds a ship from a design, given as "design_id"
+// or, for stock designs, "ship_type".
 func (gs *GameState) processBuildShipOrder(order Order) {
 	planet := gs.findPlanet(order.PlanetID)
 	if planet == nil || planet.Owner != order.PlayerID {
 		return
 	}
 	
-	shipType, ok := order.Parameters["ship_type"].(string)
+	designID, ok := order.Parameters["design_id"].(string)
+	if !ok {
+		designID, ok = order.Parameters["ship_type"].(string)
+	}
 	if !ok {
 		return
 	}
 	
-	cost := gs.getShipCost(shipType)
-	if planet.Resources.Metals >= cost.Metals && planet.Resources.Energy >= cost.Energy {
-		planet.Resources.Metals -= cost.Metals
-		planet.Resources.Energy -= cost.Energy
+	design, exists := gs.findDesign(designID, order.PlayerID)
+	if !exists || !gs.CanBuildDesign(order.PlayerID, design) {
+		return
+	}
+	
+	cost := design.Stats().Cost
+	if planet.Resources.CanAfford(cost) {
+		planet.Resources.Spend(cost)
 		
-		// Create ship (simplified - would normally add to fleet)
-		fmt.Printf("Player %s built %s on %s\n", order.PlayerID, shipType, planet.Name)
+		ship := NewSpaceshipFromDesign(gs.newID("ship"), order.PlayerID, design)
+		fleet := gs.addShipToSystem(ship, planet.StarSystemID)
+		fmt.Printf("Player %s built %s on %s (fleet %s)\n", order.PlayerID, design.Name, planet.Name, fleet.ID)
 	}
 }
 
@@ -169,10 +295,21 @@ func (gs *GameState) processBuildFacilityOrder(order Order) {
 		return
 	}
 	
+	if planet.Outpost && !extractionFacilities[facilityType] {
+		return
+	}
+	
+	// Starbases and other defences are structures in the system, not
+	// facilities on the planet
+	if _, isStructure := structureTypes[facilityType]; isStructure {
+		fmt.Printf("Player %s: %s is built with BUILD_STRUCTURE\n", order.PlayerID, facilityType)
+		gs.report(order.PlayerID, "%s on %s was not built: use BUILD_STRUCTURE for it", facilityType, planet.Name)
+		return
+	}
+	
 	cost := gs.getFacilityCost(facilityType)
-	if planet.Resources.Metals >= cost.Metals && planet.Resources.Energy >= cost.Energy {
-		planet.Resources.Metals -= cost.Metals
-		planet.Resources.Energy -= cost.Energy
+	if planet.Resources.CanAfford(cost) {
+		planet.Resources.Spend(cost)
 		
 		planet.AddFacility(facilityType, 1)
 		fmt.Printf("Player %s built %s on %s\n", order.PlayerID, facilityType, planet.Name)
@@ -193,9 +330,8 @@ func (gs *GameState) processUpgradeFacilityOrder(order Order) {
 	for i := range planet.Facilities {
 		if planet.Facilities[i].Type == facilityType {
 			cost := gs.getFacilityUpgradeCost(facilityType, planet.Facilities[i].Level)
-			if planet.Resources.Metals >= cost.Metals && planet.Resources.Energy >= cost.Energy {
-				planet.Resources.Metals -= cost.Metals
-				planet.Resources.Energy -= cost.Energy
+			if planet.Resources.CanAfford(cost) {
+				planet.Resources.Spend(cost)
 				
 				planet.Facilities[i].Level++
 				planet.Facilities[i].Output = planet.Facilities[i].Level * 10
@@ -207,26 +343,83 @@ func (gs *GameState) processUpgradeFacilityOrder(order Order) {
 	}
 }
 
-func (gs *GameState) processMoveFleetOrder(order Order) {
-	// Simplified fleet movement
-	fmt.Printf("Player %s moving fleet from %s to %s\n", 
-		order.PlayerID, order.Parameters["from"], order.Parameters["to"])
-}
-
+// processColonizeOrder settles a planet using a colony ship from one of the
+// player's fleets in the planet's system, optionally named by "fleet_id".
+// The ship is used up and its colonists become the starting population.
 func (gs *GameState) processColonizeOrder(order Order) {
 	planet := gs.findPlanet(order.PlanetID)
-	if planet == nil || planet.Owner != "" {
+	if planet == nil {
+		return
+	}
+	
+	outpost := false
+	switch {
+	case planet.Owner == order.PlayerID && planet.Outpost && planet.Habitable:
+		// An outpost that has since become habitable can be settled by its owner
+	case planet.Owner != "":
+		return
+	case order.Parameters["mode"] == "outpost" && planet.Habitable:
+		// Habitable planets are colonized, not claimed as outposts
+		gs.report(order.PlayerID, "%s is habitable: colonize it instead of founding an outpost", planet.Name)
+		return
+	case order.Parameters["mode"] == "outpost":
+		outpost = true
+	case !planet.Habitable:
 		return
 	}
 	
-	if planet.Habitable {
+	fleetID, _ := order.Parameters["fleet_id"].(string)
+	colonyShip, ok := gs.takeColonyShip(order.PlayerID, planet.StarSystemID, fleetID)
+	if !ok {
+		return
+	}
+	
+	if outpost {
 		planet.Owner = order.PlayerID
-		planet.Population = 10000
-		planet.AddFacility("Colony", 1)
+		planet.Outpost = true
+		planet.Population = 0
+		fmt.Printf("Player %s founded an outpost on %s\n", order.PlayerID, planet.Name)
+		return
+	}
+	
+	settled := planet.Outpost
+	planet.Owner = order.PlayerID
+	planet.Outpost = false
+	planet.Population = int64(colonyShip.ColonyCapacity)
+	planet.AddFacility("Colony", 1)
+	if settled {
+		fmt.Printf("Player %s settled outpost %s\n", order.PlayerID, planet.Name)
+	} else {
 		fmt.Printf("Player %s colonized %s\n", order.PlayerID, planet.Name)
 	}
 }
 
+// takeColonyShip removes a colony ship from one of the player's fleets in a
+// system, disbanding the fleet if it was the last ship. If fleetID is given
+// only that fleet is used.
+func (gs *GameState) takeColonyShip(playerID, systemID, fleetID string) (Spaceship, bool) {
+	for i := range gs.Fleets {
+		fleet := &gs.Fleets[i]
+		if fleet.Owner != playerID || fleet.Location != systemID || fleet.InTransit() {
+			continue
+		}
+		if fleetID != "" && fleet.ID != fleetID {
+			continue
+		}
+		
+		for _, ship := range fleet.GetAliveShips() {
+			if ship.ColonyCapacity > 0 {
+				fleet.RemoveShips([]string{ship.ID})
+				if len(fleet.Ships) == 0 {
+					gs.removeFleet(fleet.ID)
+				}
+				return ship, true
+			}
+		}
+	}
+	return Spaceship{}, false
+}
+
 func (gs *GameState) updateResources() {
 	fmt.Println("Updating resources...")
 	
@@ -238,6 +431,7 @@ func (gs *GameState) updateResources() {
 				// Add resource production from facilities
 				planet.Resources.Metals += planet.GetTotalProduction("MetalMine")
 				planet.Resources.Energy += planet.GetTotalProduction("PowerPlant")
+				planet.Resources.Minerals += planet.GetTotalProduction("MineralExtractor")
 				planet.Resources.Food += planet.GetTotalProduction("Farm")
 				planet.Resources.Technology += planet.GetTotalProduction("Laboratory")
 			}
@@ -294,27 +488,38 @@ func (gs *GameState) findPlanet(planetID string) *Planet {
 	return nil
 }
 
-func (gs *GameState) getShipCost(shipType string) Resources {
-	costs := map[string]Resources{
-		"Fighter":    {Metals: 50, Energy: 25, Minerals: 0, Food: 0, Technology: 0},
-		"Destroyer":  {Metals: 100, Energy: 50, Minerals: 25, Food: 0, Technology: 0},
-		"Cruiser":    {Metals: 200, Energy: 100, Minerals: 50, Food: 0, Technology: 0},
-		"Battleship": {Metals: 400, Energy: 200, Minerals: 100, Food: 0, Technology: 0},
-	}
-	
-	if cost, exists := costs[shipType]; exists {
-		return cost
+func (gs *GameState) findPlayer(playerID string) *Player {
+	for i := range gs.Players {
+		if gs.Players[i].ID == playerID {
+			return &gs.Players[i]
+		}
 	}
-	return Resources{Metals: 100, Energy: 50, Minerals: 0, Food: 0, Technology: 0}
+	return nil
+}
+
+func (gs *GameState) newID(prefix string) string {
+	gs.NextID++
+	return fmt.Sprintf("%s_%d", prefix, gs.NextID)
+}
+
+// extractionFacilities are the only facilities an outpost can run without a
+// population.
+var extractionFacilities = map[string]bool{
+	"MetalMine":        true,
+	"MineralExtractor": true,
+	"PowerPlant":       true,
 }
 
 func (gs *GameState) getFacilityCost(facilityType string) Resources {
 	costs := map[string]Resources{
-		"MetalMine":   {Metals: 50, Energy: 25, Minerals: 0, Food: 0, Technology: 0},
-		"PowerPlant":  {Metals: 75, Energy: 0, Minerals: 25, Food: 0, Technology: 0},
-		"Farm":        {Metals: 25, Energy: 10, Minerals: 0, Food: 0, Technology: 0},
-		"Factory":     {Metals: 100, Energy: 50, Minerals: 50, Food: 0, Technology: 0},
-		"Laboratory":  {Metals: 150, Energy: 75, Minerals: 25, Food: 0, Technology: 0},
+		"MetalMine":        {Metals: 50, Energy: 25, Minerals: 0, Food: 0, Technology: 0},
+		"MineralExtractor": {Metals: 60, Energy: 30, Minerals: 0, Food: 0, Technology: 0},
+		"PowerPlant":       {Metals: 75, Energy: 0, Minerals: 25, Food: 0, Technology: 0},
+		"Farm":             {Metals: 25, Energy: 10, Minerals: 0, Food: 0, Technology: 0},
+		"Factory":          {Metals: 100, Energy: 50, Minerals: 50, Food: 0, Technology: 0},
+		"Laboratory":       {Metals: 150, Energy: 75, Minerals: 25, Food: 50, Technology: 0},
+		"Shipyard":         {Metals: 150, Energy: 75, Minerals: 75, Food: 0, Technology: 0},
+		"Barracks":         {Metals: 80, Energy: 20, Minerals: 20, Food: 40, Technology: 0},
 	}
 	
 	if cost, exists := costs[facilityType]; exists {
@@ -356,8 +561,13 @@ func (gs *GameState) GetPlayerSummary(playerID string) string {
 		}
 	}
 	
-	return fmt.Sprintf("Systems: %d, Planets: %d, Population: %d, Resources: M=%d E=%d Min=%d F=%d T=%d",
-		len(systems), totalPlanets, totalPopulation,
+	credits := 0
+	if player := gs.findPlayer(playerID); player != nil {
+		credits = player.Credits
+	}
+	
+	return fmt.Sprintf("Systems: %d, Planets: %d, Population: %d, Credits: %d, Resources: M=%d E=%d Min=%d F=%d T=%d",
+		len(systems), totalPlanets, totalPopulation, credits,
 		totalResources.Metals, totalResources.Energy, totalResources.Minerals,
 		totalResources.Food, totalResources.Technology)
 }
\ No newline at end of file
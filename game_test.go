@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+// newColonyTestGame returns a game with one system holding a habitable and a
+// barren planet, and a fleet of player a carrying a colony ship and an escort.
+func newColonyTestGame() *GameState {
+	system := NewStarSystem("sys", "Sys", Star{}, Coordinates{})
+	system.AddPlanet(NewPlanet("green", "Green", "sys", "", "Terran", 1, 1, true))
+	system.AddPlanet(NewPlanet("rock", "Rock", "sys", "", "Barren", 1, 2, false))
+	
+	gs := &GameState{Galaxy: Galaxy{StarSystems: []StarSystem{system}}}
+	gs.Fleets = []Fleet{NewFleet("fleet_1", "a", "sys", []Spaceship{
+		NewSpaceshipFromDesign("ship_1", "a", stockDesigns["Fighter"]),
+		NewSpaceshipFromDesign("ship_2", "a", stockDesigns["ColonyShip"]),
+	})}
+	return gs
+}
+
+func TestColonizeUsesColonyShip(t *testing.T) {
+	gs := newColonyTestGame()
+	gs.processColonizeOrder(Order{PlayerID: "a", PlanetID: "green"})
+	
+	planet := gs.findPlanet("green")
+	if planet.Owner != "a" || planet.Population != 10000 {
+		t.Errorf("planet = owner %q population %d, want a with 10000", planet.Owner, planet.Population)
+	}
+	if ships := gs.Fleets[0].Ships; len(ships) != 1 || ships[0].ID != "ship_1" {
+		t.Errorf("fleet ships = %+v, want only the escort left", ships)
+	}
+}
+
+func TestColonizeWithoutColonyShip(t *testing.T) {
+	tests := map[string]func(gs *GameState) Order{
+		"another player's ship": func(gs *GameState) Order {
+			return Order{PlayerID: "b", PlanetID: "green"}
+		},
+		"fleet still travelling": func(gs *GameState) Order {
+			gs.Fleets[0].Destination = "elsewhere"
+			gs.Fleets[0].TurnsRemaining = 2
+			return Order{PlayerID: "a", PlanetID: "green"}
+		},
+		"named fleet has none": func(gs *GameState) Order {
+			return Order{PlayerID: "a", PlanetID: "green", Parameters: map[string]interface{}{"fleet_id": "fleet_2"}}
+		},
+		"planet not habitable": func(gs *GameState) Order {
+			return Order{PlayerID: "a", PlanetID: "rock"}
+		},
+	}
+	
+	for name, setup := range tests {
+		t.Run(name, func(t *testing.T) {
+			gs := newColonyTestGame()
+			order := setup(gs)
+			
+			gs.processColonizeOrder(order)
+			
+			if owner := gs.findPlanet(order.PlanetID).Owner; owner != "" {
+				t.Errorf("planet owned by %q, want unclaimed", owner)
+			}
+			if len(gs.Fleets[0].Ships) != 2 {
+				t.Errorf("colony ship used up without a colony")
+			}
+		})
+	}
+}
+
+func TestOutpostDisbandsEmptyFleet(t *testing.T) {
+	gs := newColonyTestGame()
+	gs.Fleets[0].RemoveShips([]string{"ship_1"})
+	gs.processColonizeOrder(Order{PlayerID: "a", PlanetID: "rock", Parameters: map[string]interface{}{"mode": "outpost"}})
+	
+	planet := gs.findPlanet("rock")
+	if planet.Owner != "a" || !planet.Outpost || planet.Population != 0 {
+		t.Errorf("planet = %+v, want an empty outpost of a", planet)
+	}
+	if len(gs.Fleets) != 0 {
+		t.Errorf("fleets = %+v, want the emptied fleet disbanded", gs.Fleets)
+	}
+}
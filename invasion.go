@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const (
+	garrisonPerPopulation = 20000
+	barracksGarrison      = 15
+	defenderBonus         = 1.25
+	maxGroundRounds       = 50
+)
+
+// Garrison is the number of troops defending a planet, drawn from its
+// population and the facilities that support it. Barracks count for most.
+func (p *Planet) Garrison() int {
+	garrison := int(p.Population / garrisonPerPopulation)
+	for _, facility := range p.Facilities {
+		if facility.Type == "Barracks" {
+			garrison += facility.Level * barracksGarrison
+		} else {
+			garrison += facility.Level * 2
+		}
+	}
+	return garrison
+}
+
+// processInvadeOrder lands the troops of every troop ship the player has in
+// the planet's system, or only those of "fleet_id", and fights for the
+// planet. The transports are used up either way. A successful invasion takes
+// over the planet's facilities, or destroys them if "raze" is set.
+func (gs *GameState) processInvadeOrder(order Order) {
+	planet := gs.findPlanet(order.PlanetID)
+	if planet == nil || planet.Owner == "" || !gs.areHostile(planet.Owner, order.PlayerID) {
+		return
+	}
+	
+	if gs.hasDefences(planet.StarSystemID, planet.Owner) {
+		gs.report(order.PlayerID, "Invasion of %s called off: its defences are still standing", planet.Name)
+		return
+	}
+	
+	fleetID, _ := order.Parameters["fleet_id"].(string)
+	troops := gs.landTroops(order.PlayerID, planet.StarSystemID, fleetID)
+	if troops == 0 {
+		return
+	}
+	
+	defender := planet.Owner
+	garrison := planet.Garrison()
+	attackersLeft, defendersLeft := resolveGroundCombat(troops, garrison)
+	
+	if attackersLeft == 0 || defendersLeft > 0 {
+		fmt.Printf("Player %s failed to invade %s\n", order.PlayerID, planet.Name)
+		gs.report(order.PlayerID, "Invasion of %s failed: %d troops lost against a garrison of %d", planet.Name, troops, garrison)
+		gs.report(defender, "Repelled an invasion of %s by %s", planet.Name, order.PlayerID)
+		return
+	}
+	
+	planet.Owner = order.PlayerID
+	planet.Population -= planet.Population / 10
+	
+	raze, _ := order.Parameters["raze"].(bool)
+	if raze {
+		planet.Facilities = []Facility{}
+		if !planet.Outpost {
+			planet.AddFacility("Colony", 1)
+		}
+	}
+	
+	fmt.Printf("Player %s captured %s from %s\n", order.PlayerID, planet.Name, defender)
+	if raze {
+		gs.report(order.PlayerID, "Captured %s and razed its facilities (%d of %d troops survived)", planet.Name, attackersLeft, troops)
+	} else {
+		gs.report(order.PlayerID, "Captured %s with its facilities (%d of %d troops survived)", planet.Name, attackersLeft, troops)
+	}
+	gs.report(defender, "Lost %s to an invasion by %s", planet.Name, order.PlayerID)
+}
+
+// landTroops unloads every troop ship the player has in a system, or in the
+// given fleet, and returns the number of troops landed.
+func (gs *GameState) landTroops(playerID, systemID, fleetID string) int {
+	troops := 0
+	for i := range gs.Fleets {
+		fleet := &gs.Fleets[i]
+		if fleet.Owner != playerID || fleet.Location != systemID || fleet.InTransit() {
+			continue
+		}
+		if fleetID != "" && fleet.ID != fleetID {
+			continue
+		}
+		
+		var transports []string
+		for _, ship := range fleet.GetAliveShips() {
+			if ship.Troops > 0 {
+				troops += ship.Troops
+				transports = append(transports, ship.ID)
+			}
+		}
+		if len(transports) > 0 {
+			fleet.RemoveShips(transports)
+		}
+	}
+	
+	gs.removeDestroyedShips()
+	return troops
+}
+
+// resolveGroundCombat fights in rounds until one side is wiped out. Each
+// round both sides inflict casualties in proportion to their strength, with
+// the defenders fighting harder on their own ground.
+func resolveGroundCombat(attackers, defenders int) (int, int) {
+	for round := 0; round < maxGroundRounds && attackers > 0 && defenders > 0; round++ {
+		attackerHits := int(float64(attackers) * 0.2 * (0.5 + rand.Float64()))
+		defenderHits := int(float64(defenders) * 0.2 * defenderBonus * (0.5 + rand.Float64()))
+		attackers = max(0, attackers-max(1, defenderHits))
+		defenders = max(0, defenders-max(1, attackerHits))
+	}
+	return attackers, defenders
+}
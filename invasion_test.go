@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGarrison(t *testing.T) {
+	planet := NewPlanet("planet_1", "One", "sys", "b", "Terran", 1, 1, true)
+	planet.Population = 100000
+	if got := planet.Garrison(); got != 5 {
+		t.Errorf("garrison of 100000 people = %d, want 5", got)
+	}
+	
+	planet.AddFacility("Colony", 1)
+	planet.AddFacility("Barracks", 2)
+	if got := planet.Garrison(); got != 5+2+30 {
+		t.Errorf("garrison with a colony and level 2 barracks = %d, want 37", got)
+	}
+}
+
+func TestResolveGroundCombatOverwhelmingForce(t *testing.T) {
+	attackers, defenders := resolveGroundCombat(1000, 1)
+	if defenders != 0 || attackers < 990 {
+		t.Errorf("1000 against 1 left %d attackers and %d defenders", attackers, defenders)
+	}
+	
+	attackers, defenders = resolveGroundCombat(1, 1000)
+	if attackers != 0 || defenders < 990 {
+		t.Errorf("1 against 1000 left %d attackers and %d defenders", attackers, defenders)
+	}
+}
+
+// newInvasionTestGame puts a planet of player b, guarded by barracks, in the
+// same system as a fleet of player a with the given number of transports.
+func newInvasionTestGame(transports int) *GameState {
+	planet := NewPlanet("planet_1", "One", "sys", "b", "Terran", 1, 1, true)
+	planet.Population = 50000
+	planet.AddFacility("Colony", 1)
+	planet.AddFacility("Barracks", 1)
+	system := NewStarSystem("sys", "Sys", Star{}, Coordinates{})
+	system.AddPlanet(planet)
+	
+	ships := []Spaceship{NewSpaceshipFromDesign("escort", "a", stockDesigns["Fighter"])}
+	for i := 0; i < transports; i++ {
+		ships = append(ships, NewSpaceshipFromDesign(fmt.Sprintf("transport_%d", i), "a", stockDesigns["TroopTransport"]))
+	}
+	
+	return &GameState{
+		Galaxy:  Galaxy{StarSystems: []StarSystem{system}},
+		Fleets:  []Fleet{NewFleet("fleet_1", "a", "sys", ships)},
+		Reports: make(map[string][]string),
+	}
+}
+
+func TestInvadeCapturesPlanet(t *testing.T) {
+	for _, raze := range []bool{false, true} {
+		gs := newInvasionTestGame(10)
+		gs.processInvadeOrder(Order{PlayerID: "a", PlanetID: "planet_1", Parameters: map[string]interface{}{"raze": raze}})
+		
+		planet := gs.findPlanet("planet_1")
+		if planet.Owner != "a" {
+			t.Fatalf("raze %v: owner = %q, want a", raze, planet.Owner)
+		}
+		if planet.Population != 45000 {
+			t.Errorf("raze %v: population = %d, want 45000", raze, planet.Population)
+		}
+		if got := planet.HasFacility("Barracks"); got == raze {
+			t.Errorf("raze %v: barracks kept = %v", raze, got)
+		}
+		if len(gs.Fleets[0].Ships) != 1 {
+			t.Errorf("raze %v: %d ships left, want the transports used up", raze, len(gs.Fleets[0].Ships))
+		}
+		if len(gs.Reports["a"]) != 1 || len(gs.Reports["b"]) != 1 {
+			t.Errorf("raze %v: reports = %v", raze, gs.Reports)
+		}
+	}
+}
+
+func TestInvadeFailsAgainstGarrison(t *testing.T) {
+	gs := newInvasionTestGame(0)
+	ship := NewSpaceship("dropship", "Dropship", "a", 50, 0, 0, 0, 5)
+	ship.Troops = 1
+	gs.Fleets[0].AddShip(ship)
+	
+	gs.processInvadeOrder(Order{PlayerID: "a", PlanetID: "planet_1"})
+	
+	if owner := gs.findPlanet("planet_1").Owner; owner != "b" {
+		t.Errorf("owner = %q after a failed invasion, want b", owner)
+	}
+	if len(gs.Fleets[0].Ships) != 1 {
+		t.Errorf("the dropship survived a failed landing")
+	}
+}
+
+func TestInvadeNeedsTroops(t *testing.T) {
+	gs := newInvasionTestGame(0)
+	gs.processInvadeOrder(Order{PlayerID: "a", PlanetID: "planet_1"})
+	if owner := gs.findPlanet("planet_1").Owner; owner != "b" || len(gs.Reports["a"]) != 0 {
+		t.Errorf("invasion without transports went ahead")
+	}
+}
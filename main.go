@@ -3,21 +3,39 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
+	"time"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		runBattleSimulator(os.Args[2:])
+		return
+	}
+	
 	serverMode := flag.Bool("server", false, "Run as server")
+	tacticalRanges := flag.Bool("tactical-ranges", false, "Fight battles with engagement ranges")
+	resolution := flag.String("resolution", ResolutionSequential, "Combat resolution: sequential or simultaneous")
 	flag.Parse()
 	
+	if !validResolution(*resolution) {
+		fmt.Printf("unknown combat resolution %q\n", *resolution)
+		os.Exit(1)
+	}
+	
+	rules := DefaultGameRules()
+	rules.TacticalRanges = *tacticalRanges
+	rules.CombatResolution = *resolution
+	
 	if *serverMode {
-		runServer()
+		runServer(rules)
 		return
 	}
 	
-	runSimulation()
+	runSimulation(rules)
 }
 
-func runServer() {
+func runServer(rules GameRules) {
 	players := []Player{
 		{ID: "player1", Name: "Terran Federation"},
 		{ID: "player2", Name: "Zephyrian Empire"},
@@ -26,10 +44,55 @@ func runServer() {
 	}
 	
 	server := NewGameServer(players, 20, 50, 30) // 30 second turns
+	server.gameState.Rules = rules
 	server.StartServer(8080)
 }
 
-func runSimulation() {
+// runBattleSimulator fights the fleets given on the command line against
+// each other many times and prints the odds, for example:
+//
+//	galaxy simulate -battles 5000 p1=Destroyer:3,Fighter:2 p2=Cruiser:2
+func runBattleSimulator(args []string) {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	battles := flags.Int("battles", defaultSimulatedBattles, "Number of battles to fight")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "Seed for the first battle")
+	tacticalRanges := flags.Bool("tactical-ranges", false, "Fight battles with engagement ranges")
+	resolution := flags.String("resolution", ResolutionSequential, "Combat resolution: sequential or simultaneous")
+	flags.Parse(args)
+	
+	if !validResolution(*resolution) {
+		fmt.Printf("unknown combat resolution %q\n", *resolution)
+		os.Exit(1)
+	}
+	
+	var compositions []FleetComposition
+	for _, arg := range flags.Args() {
+		composition, err := parseFleetComposition(arg)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		compositions = append(compositions, composition)
+	}
+	
+	fleets, err := buildSimulatedFleets(compositions, func(designID, owner string) (ShipDesign, bool) {
+		design, exists := stockDesigns[designID]
+		return design, exists
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if *battles < 1 {
+		fmt.Println("need at least one battle")
+		os.Exit(1)
+	}
+	
+	config := BattleConfig{Ranges: *tacticalRanges, Resolution: *resolution}
+	PrintSimulationResult(SimulateBattle(fleets, *battles, *seed, config))
+}
+
+func runSimulation(rules GameRules) {
 	fmt.Println("Galaxy Strategy Game - Turn-Based Test")
 	fmt.Println("======================================")
 
@@ -45,6 +108,7 @@ func runSimulation() {
 	}
 
 	gameState := NewGameState(players, 15, 10)
+	gameState.Rules = rules
 	
 	fmt.Printf("\nGame initialized: %d systems, %d turns max\n", len(gameState.Galaxy.StarSystems), gameState.MaxTurns)
 
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+var ResourceTypes = []string{"Metals", "Energy", "Minerals", "Food", "Technology"}
+
+var basePrices = map[string]int{
+	"Metals":     10,
+	"Energy":     8,
+	"Minerals":   12,
+	"Food":       5,
+	"Technology": 25,
+}
+
+const offerLifetime = 5
+
+type Market struct {
+	Prices map[string]int
+	Supply map[string]int
+	Demand map[string]int
+	Offers []MarketOffer
+}
+
+type MarketOffer struct {
+	ID         string
+	PlayerID   string
+	PlanetID   string
+	Resource   string
+	Side       string
+	Quantity   int
+	LimitPrice int
+	Expires    int
+}
+
+type TradeRoute struct {
+	ID         string
+	Owner      string
+	FromSystem string
+	ToSystem   string
+	Income     int
+	Disrupted  bool
+}
+
+func NewMarket() Market {
+	prices := make(map[string]int)
+	for resource, price := range basePrices {
+		prices[resource] = price
+	}
+	
+	return Market{
+		Prices: prices,
+		Supply: make(map[string]int),
+		Demand: make(map[string]int),
+		Offers: []MarketOffer{},
+	}
+}
+
+func (gs *GameState) processMarketOrder(order Order) {
+	planet := gs.findPlanet(order.PlanetID)
+	if planet == nil || planet.Owner != order.PlayerID {
+		return
+	}
+	
+	resource, ok := order.Parameters["resource"].(string)
+	if !ok || planet.Resources.field(resource) == nil {
+		return
+	}
+	
+	quantity, ok := order.Parameters["quantity"].(float64)
+	if !ok || quantity <= 0 {
+		return
+	}
+	
+	side := "buy"
+	if OrderType(order.OrderType) == OrderMarketSell {
+		side = "sell"
+	}
+	
+	limitPrice := gs.Market.Prices[resource]
+	if price, ok := order.Parameters["price"].(float64); ok && price > 0 {
+		limitPrice = int(price)
+	}
+	
+	gs.Market.Offers = append(gs.Market.Offers, MarketOffer{
+		ID:         gs.newID("offer"),
+		PlayerID:   order.PlayerID,
+		PlanetID:   planet.ID,
+		Resource:   resource,
+		Side:       side,
+		Quantity:   int(quantity),
+		LimitPrice: limitPrice,
+		Expires:    gs.CurrentTurn + offerLifetime,
+	})
+	fmt.Printf("Player %s posted %s offer for %d %s at %d\n",
+		order.PlayerID, side, int(quantity), resource, limitPrice)
+}
+
+func (gs *GameState) processTradeRouteOrder(order Order) {
+	if OrderType(order.OrderType) == OrderCancelTradeRoute {
+		routeID, _ := order.Parameters["route_id"].(string)
+		for i, route := range gs.TradeRoutes {
+			if route.ID == routeID && route.Owner == order.PlayerID {
+				gs.TradeRoutes = append(gs.TradeRoutes[:i], gs.TradeRoutes[i+1:]...)
+				fmt.Printf("Player %s cancelled trade route %s\n", order.PlayerID, routeID)
+				return
+			}
+		}
+		return
+	}
+	
+	fromID, _ := order.Parameters["from"].(string)
+	toID, _ := order.Parameters["to"].(string)
+	from := gs.Galaxy.GetSystemByID(fromID)
+	to := gs.Galaxy.GetSystemByID(toID)
+	if from == nil || to == nil || from.ID == to.ID {
+		return
+	}
+	if from.ControlledBy != order.PlayerID || to.ControlledBy != order.PlayerID {
+		return
+	}
+	
+	for _, route := range gs.TradeRoutes {
+		if route.Owner == order.PlayerID &&
+			(route.FromSystem == from.ID && route.ToSystem == to.ID ||
+				route.FromSystem == to.ID && route.ToSystem == from.ID) {
+			return
+		}
+	}
+	
+	route := TradeRoute{
+		ID:         gs.newID("route"),
+		Owner:      order.PlayerID,
+		FromSystem: from.ID,
+		ToSystem:   to.ID,
+		Income:     tradeRouteIncome(from, to),
+	}
+	gs.TradeRoutes = append(gs.TradeRoutes, route)
+	fmt.Printf("Player %s opened trade route %s between %s and %s\n",
+		order.PlayerID, route.ID, from.Name, to.Name)
+}
+
+// tradeRouteIncome pays more for longer routes, since goods carried further
+// fetch a better margin.
+func tradeRouteIncome(from, to *StarSystem) int {
+	return 10 + int(CalculateDistance(from.Coordinates, to.Coordinates))
+}
+
+func (gs *GameState) updateMarket() {
+	fmt.Println("Clearing market...")
+	
+	market := &gs.Market
+	market.Supply = make(map[string]int)
+	market.Demand = make(map[string]int)
+	
+	for _, offer := range market.Offers {
+		if offer.Side == "sell" {
+			market.Supply[offer.Resource] += offer.Quantity
+		} else {
+			market.Demand[offer.Resource] += offer.Quantity
+		}
+	}
+	
+	// Offers that match the current price are filled. Player offers on both
+	// sides of the book are settled at the same price, and neutral traders
+	// absorb whatever imbalance is left over.
+	remaining := []MarketOffer{}
+	for _, offer := range market.Offers {
+		if gs.fillOffer(&offer) && offer.Quantity > 0 && offer.Expires > gs.CurrentTurn {
+			remaining = append(remaining, offer)
+		}
+	}
+	market.Offers = remaining
+	
+	for _, resource := range ResourceTypes {
+		market.Prices[resource] = adjustPrice(market.Prices[resource], basePrices[resource],
+			market.Supply[resource], market.Demand[resource])
+	}
+}
+
+// fillOffer trades as much of the offer as possible at the current market
+// price. It returns false if the offer can no longer be honoured.
+func (gs *GameState) fillOffer(offer *MarketOffer) bool {
+	player := gs.findPlayer(offer.PlayerID)
+	planet := gs.findPlanet(offer.PlanetID)
+	if player == nil || planet == nil || planet.Owner != offer.PlayerID {
+		return false
+	}
+	
+	price := gs.Market.Prices[offer.Resource]
+	stock := planet.Resources.field(offer.Resource)
+	
+	quantity := 0
+	verb := "bought"
+	if offer.Side == "sell" {
+		verb = "sold"
+		if price < offer.LimitPrice {
+			return true
+		}
+		quantity = min(offer.Quantity, *stock)
+		*stock -= quantity
+		player.Credits += quantity * price
+	} else {
+		if price > offer.LimitPrice {
+			return true
+		}
+		quantity = min(offer.Quantity, player.Credits/price)
+		*stock += quantity
+		player.Credits -= quantity * price
+	}
+	
+	if quantity > 0 {
+		offer.Quantity -= quantity
+		fmt.Printf("Player %s %s %d %s at %d\n", offer.PlayerID, verb, quantity, offer.Resource, price)
+	}
+	return true
+}
+
+// adjustPrice moves a price with the balance of supply and demand, and lets
+// it drift back toward the base price when the market is quiet.
+func adjustPrice(price, basePrice, supply, demand int) int {
+	newPrice := float64(price)
+	if supply+demand > 0 {
+		imbalance := float64(demand-supply) / float64(demand+supply)
+		newPrice *= 1 + 0.2*imbalance
+	} else {
+		newPrice += (float64(basePrice) - newPrice) * 0.1
+	}
+	
+	return max(1, int(math.Round(newPrice)))
+}
+
+func (gs *GameState) updateTradeRoutes() {
+	fmt.Println("Updating trade routes...")
+	
+	for i := range gs.TradeRoutes {
+		route := &gs.TradeRoutes[i]
+		route.Disrupted = gs.isRouteDisrupted(*route)
+		if route.Disrupted {
+			fmt.Printf("Trade route %s of player %s is disrupted\n", route.ID, route.Owner)
+			continue
+		}
+		
+		if player := gs.findPlayer(route.Owner); player != nil {
+			player.Credits += route.Income
+		}
+	}
+}
+
+// isRouteDisrupted reports whether a route can't run this turn, either
+// because its owner no longer controls both ends or because an enemy fleet
+// sits at one of them.
+func (gs *GameState) isRouteDisrupted(route TradeRoute) bool {
+	for _, systemID := range []string{route.FromSystem, route.ToSystem} {
+		system := gs.Galaxy.GetSystemByID(systemID)
+		if system == nil || system.ControlledBy != route.Owner {
+			return true
+		}
+		if gs.hasHostileFleet(systemID, route.Owner) {
+			return true
+		}
+	}
+	return false
+}
+
+func (gs *GameState) GetTradeRoutesByOwner(owner string) []TradeRoute {
+	var routes []TradeRoute
+	for _, route := range gs.TradeRoutes {
+		if route.Owner == owner {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
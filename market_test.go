@@ -0,0 +1,91 @@
+package main
+
+import "testing"
+
+func TestAdjustPrice(t *testing.T) {
+	tests := []struct {
+		name   string
+		price  int
+		supply int
+		demand int
+		want   int
+	}{
+		{"all demand raises the price by a fifth", 10, 0, 50, 12},
+		{"all supply lowers the price by a fifth", 10, 50, 0, 8},
+		{"balanced book holds the price", 10, 30, 30, 10},
+		{"quiet market drifts up toward base", 20, 0, 0, 21},
+		{"quiet market drifts down toward base", 50, 0, 0, 48},
+		{"never drops below 1", 1, 100, 0, 1},
+	}
+	
+	for _, test := range tests {
+		if got := adjustPrice(test.price, 25, test.supply, test.demand); got != test.want {
+			t.Errorf("%s: adjustPrice(%d, 25, %d, %d) = %d, want %d",
+				test.name, test.price, test.supply, test.demand, got, test.want)
+		}
+	}
+}
+
+func newMarketTestGame() *GameState {
+	system := NewStarSystem("system_test", "Test", Star{}, Coordinates{})
+	planet := NewPlanet("planet_a", "A", system.ID, "a", "Terran", 1, 1, true)
+	planet.Resources = Resources{Metals: 100}
+	system.AddPlanet(planet)
+	
+	gs := &GameState{
+		Players: []Player{{ID: "a", Name: "A", Credits: 1000}},
+		Market:  NewMarket(),
+	}
+	gs.Galaxy.AddStarSystem(system)
+	return gs
+}
+
+func TestUpdateMarketFillsOffers(t *testing.T) {
+	gs := newMarketTestGame()
+	gs.processMarketOrder(Order{
+		PlayerID:   "a",
+		OrderType:  string(OrderMarketSell),
+		PlanetID:   "planet_a",
+		Parameters: map[string]interface{}{"resource": "Metals", "quantity": 40.0},
+	})
+	gs.processMarketOrder(Order{
+		PlayerID:   "a",
+		OrderType:  string(OrderMarketBuy),
+		PlanetID:   "planet_a",
+		Parameters: map[string]interface{}{"resource": "Technology", "quantity": 5.0, "price": 10.0},
+	})
+	
+	gs.updateMarket()
+	
+	planet := gs.findPlanet("planet_a")
+	if planet.Resources.Metals != 60 {
+		t.Errorf("metals = %d, want 60 after selling 40", planet.Resources.Metals)
+	}
+	if credits := gs.findPlayer("a").Credits; credits != 1400 {
+		t.Errorf("credits = %d, want 1400 after selling 40 at 10", credits)
+	}
+	if price := gs.Market.Prices["Metals"]; price != 8 {
+		t.Errorf("metals price = %d, want 8 after a sell-only turn", price)
+	}
+	
+	// The technology bid is below the market price, so it waits
+	if len(gs.Market.Offers) != 1 || gs.Market.Offers[0].Resource != "Technology" {
+		t.Fatalf("offers left = %+v, want only the technology bid", gs.Market.Offers)
+	}
+	if planet.Resources.Technology != 0 {
+		t.Errorf("technology = %d, want the bid unfilled", planet.Resources.Technology)
+	}
+}
+
+func TestMarketOrderRejectsOtherPlayersPlanets(t *testing.T) {
+	gs := newMarketTestGame()
+	gs.processMarketOrder(Order{
+		PlayerID:   "b",
+		OrderType:  string(OrderMarketSell),
+		PlanetID:   "planet_a",
+		Parameters: map[string]interface{}{"resource": "Metals", "quantity": 40.0},
+	})
+	if len(gs.Market.Offers) != 0 {
+		t.Errorf("offers = %+v, want none", gs.Market.Offers)
+	}
+}
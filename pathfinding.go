@@ -0,0 +1,150 @@
+package main
+
+import "container/heap"
+
+type pathNode struct {
+	systemID string
+	cost     float64
+	estimate float64
+	index    int
+}
+
+type pathQueue []*pathNode
+
+func (q pathQueue) Len() int           { return len(q) }
+func (q pathQueue) Less(i, j int) bool { return q[i].estimate < q[j].estimate }
+func (q pathQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *pathQueue) Push(x interface{}) {
+	node := x.(*pathNode)
+	node.index = len(*q)
+	*q = append(*q, node)
+}
+
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
+
+// FindPath finds the shortest path between two systems along hyperlanes
+// using A*, with straight-line distance as the heuristic. The path includes
+// both ends. Systems for which avoid returns true are never entered, except
+// for the destination itself. It returns nil if there is no path.
+func (g *Galaxy) FindPath(fromID, toID string, avoid func(*StarSystem) bool) []string {
+	from := g.GetSystemByID(fromID)
+	to := g.GetSystemByID(toID)
+	if from == nil || to == nil {
+		return nil
+	}
+	if from.ID == to.ID {
+		return []string{from.ID}
+	}
+	
+	costs := map[string]float64{from.ID: 0}
+	previous := make(map[string]string)
+	closed := make(map[string]bool)
+	
+	queue := &pathQueue{}
+	heap.Push(queue, &pathNode{
+		systemID: from.ID,
+		estimate: CalculateDistance(from.Coordinates, to.Coordinates),
+	})
+	
+	for queue.Len() > 0 {
+		node := heap.Pop(queue).(*pathNode)
+		if node.systemID == to.ID {
+			path := []string{to.ID}
+			for id := to.ID; id != from.ID; {
+				id = previous[id]
+				path = append([]string{id}, path...)
+			}
+			return path
+		}
+		if closed[node.systemID] {
+			continue
+		}
+		closed[node.systemID] = true
+		
+		current := g.GetSystemByID(node.systemID)
+		for _, neighbourID := range current.Connections {
+			neighbour := g.GetSystemByID(neighbourID)
+			if neighbour == nil || closed[neighbourID] {
+				continue
+			}
+			if neighbourID != to.ID && avoid != nil && avoid(neighbour) {
+				continue
+			}
+			
+			cost := node.cost + CalculateDistance(current.Coordinates, neighbour.Coordinates)
+			if known, seen := costs[neighbourID]; seen && known <= cost {
+				continue
+			}
+			costs[neighbourID] = cost
+			previous[neighbourID] = current.ID
+			heap.Push(queue, &pathNode{
+				systemID: neighbourID,
+				cost:     cost,
+				estimate: cost + CalculateDistance(neighbour.Coordinates, to.Coordinates),
+			})
+		}
+	}
+	return nil
+}
+
+// isHostileSystem reports whether a system is known to be dangerous for the
+// player: held by an enemy, or with an enemy fleet present.
+func (gs *GameState) isHostileSystem(system *StarSystem, playerID string) bool {
+	if system.ControlledBy != "" && gs.areHostile(system.ControlledBy, playerID) {
+		return true
+	}
+	return gs.hasHostileFleet(system.ID, playerID)
+}
+
+// planRoute plans a path for a fleet through each waypoint in turn. A fleet
+// in transit plans from the system it is heading for. The returned path
+// starts at that system.
+func (gs *GameState) planRoute(fleet *Fleet, waypoints []string, avoidHostile bool) []string {
+	start := fleet.Location
+	if fleet.InTransit() {
+		start = fleet.Destination
+	}
+	
+	var avoid func(*StarSystem) bool
+	if avoidHostile {
+		avoid = func(system *StarSystem) bool {
+			return gs.isHostileSystem(system, fleet.Owner)
+		}
+	}
+	
+	path := []string{start}
+	for _, waypoint := range waypoints {
+		leg := gs.Galaxy.FindPath(path[len(path)-1], waypoint, avoid)
+		if leg == nil {
+			return nil
+		}
+		path = append(path, leg[1:]...)
+	}
+	return path
+}
+
+// routeETA is the number of turns until a fleet reaches the end of a path
+// from planRoute.
+func (gs *GameState) routeETA(fleet *Fleet, path []string) int {
+	eta := 0
+	if fleet.InTransit() {
+		eta = fleet.TurnsRemaining
+	}
+	
+	for i := 1; i < len(path); i++ {
+		from := gs.Galaxy.GetSystemByID(path[i-1])
+		to := gs.Galaxy.GetSystemByID(path[i])
+		eta += travelTurns(fleet, from, to)
+	}
+	return eta
+}
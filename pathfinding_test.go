@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newTestGalaxy builds a galaxy of empty systems at the given coordinates,
+// joined by the given hyperlanes.
+func newTestGalaxy(coordinates map[string]Coordinates, lanes [][2]string) Galaxy {
+	galaxy := Galaxy{ID: "galaxy_test", Name: "Test"}
+	for id, coords := range coordinates {
+		galaxy.AddStarSystem(NewStarSystem(id, id, Star{}, coords))
+	}
+	for _, lane := range lanes {
+		from := galaxy.GetSystemByID(lane[0])
+		to := galaxy.GetSystemByID(lane[1])
+		from.Connections = append(from.Connections, to.ID)
+		to.Connections = append(to.Connections, from.ID)
+	}
+	return galaxy
+}
+
+func TestFindPath(t *testing.T) {
+	// Three short jumps along the bottom, or two long ones through d
+	galaxy := newTestGalaxy(map[string]Coordinates{
+		"a": {X: 0, Y: 0},
+		"b": {X: 10, Y: 0},
+		"c": {X: 20, Y: 0},
+		"d": {X: 15, Y: 20},
+		"e": {X: 30, Y: 0},
+		"f": {X: 50, Y: 50},
+	}, [][2]string{
+		{"a", "b"}, {"b", "c"}, {"c", "e"},
+		{"a", "d"}, {"d", "e"},
+	})
+	
+	tests := []struct {
+		name  string
+		from  string
+		to    string
+		avoid []string
+		want  []string
+	}{
+		{name: "shortest distance beats fewest jumps", from: "a", to: "e", want: []string{"a", "b", "c", "e"}},
+		{name: "works both ways", from: "e", to: "a", want: []string{"e", "c", "b", "a"}},
+		{name: "neighbour", from: "a", to: "b", want: []string{"a", "b"}},
+		{name: "same system", from: "c", to: "c", want: []string{"c"}},
+		{name: "avoided systems are routed around", from: "a", to: "e", avoid: []string{"b"}, want: []string{"a", "d", "e"}},
+		{name: "avoided destination can still be reached", from: "a", to: "b", avoid: []string{"b"}, want: []string{"a", "b"}},
+		{name: "avoiding every route", from: "a", to: "e", avoid: []string{"b", "d"}, want: nil},
+		{name: "unconnected system", from: "a", to: "f", want: nil},
+		{name: "unknown system", from: "a", to: "z", want: nil},
+	}
+	
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var avoid func(*StarSystem) bool
+			if test.avoid != nil {
+				avoid = func(system *StarSystem) bool {
+					for _, id := range test.avoid {
+						if system.ID == id {
+							return true
+						}
+					}
+					return false
+				}
+			}
+			if got := galaxy.FindPath(test.from, test.to, avoid); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("FindPath(%s, %s) = %v, want %v", test.from, test.to, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPlanRoute(t *testing.T) {
+	gs := &GameState{
+		Galaxy: newTestGalaxy(map[string]Coordinates{
+			"a": {X: 0, Y: 0},
+			"b": {X: 10, Y: 0},
+			"c": {X: 20, Y: 0},
+			"d": {X: 15, Y: 20},
+			"e": {X: 30, Y: 0},
+		}, [][2]string{
+			{"a", "b"}, {"b", "c"}, {"c", "e"},
+			{"a", "d"}, {"d", "e"},
+		}),
+	}
+	gs.Galaxy.GetSystemByID("b").ControlledBy = "enemy"
+	fleet := NewFleet("fleet_1", "a", "a", []Spaceship{NewSpaceship("ship_1", "Ship", "a", 10, 0, 0, 0, 10)})
+	
+	if got, want := gs.planRoute(&fleet, []string{"c", "a"}, false), []string{"a", "b", "c", "b", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("through waypoints = %v, want %v", got, want)
+	}
+	if got, want := gs.planRoute(&fleet, []string{"e"}, true), []string{"a", "d", "e"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("avoiding hostile systems = %v, want %v", got, want)
+	}
+	
+	// A fleet in transit plans from where it is heading
+	fleet.Destination = "c"
+	fleet.TurnsRemaining = 1
+	if got, want := gs.planRoute(&fleet, []string{"e"}, false), []string{"c", "e"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("in transit = %v, want %v", got, want)
+	}
+	if eta := gs.routeETA(&fleet, []string{"c", "e"}); eta != 2 {
+		t.Errorf("ETA = %d, want 1 turn to c and 1 on to e", eta)
+	}
+}
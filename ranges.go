@@ -0,0 +1,191 @@
+package main
+
+// Engagement ranges for the optional tactical layer, from closest to
+// furthest. Battles open at long range, and every round each pair of hostile
+// fleets may close or open the range by one band.
+const (
+	RangeShort  = "short"
+	RangeMedium = "medium"
+	RangeLong   = "long"
+)
+
+var rangeBands = []string{RangeShort, RangeMedium, RangeLong}
+
+// RangeChange records a pair of fleets moving from one range band to
+// another during a round.
+type RangeChange struct {
+	Fleets []string
+	From   string
+	To     string
+}
+
+func rangeIndex(band string) int {
+	for i, name := range rangeBands {
+		if name == band {
+			return i
+		}
+	}
+	return -1
+}
+
+// reaches reports whether a weapon is effective at a range band. Weapons
+// with no listed ranges are effective at every range.
+func (w WeaponType) reaches(band string) bool {
+	if len(w.Ranges) == 0 {
+		return true
+	}
+	for _, name := range w.Ranges {
+		if name == band {
+			return true
+		}
+	}
+	return false
+}
+
+// battleRanges tracks the range between every pair of hostile fleets in a
+// battle.
+type battleRanges struct {
+	fleets    []Fleet
+	bands     map[[2]int]int
+	preferred []int
+	withdrawn map[int]bool
+}
+
+func newBattleRanges(fleets []Fleet, hostile func(playerA, playerB string) bool) *battleRanges {
+	ranges := &battleRanges{
+		fleets:    fleets,
+		bands:     make(map[[2]int]int),
+		preferred: make([]int, len(fleets)),
+		withdrawn: make(map[int]bool),
+	}
+	
+	for i := range fleets {
+		ranges.preferred[i] = preferredRange(fleets[i])
+		for j := i + 1; j < len(fleets); j++ {
+			if hostile(fleets[i].Owner, fleets[j].Owner) {
+				ranges.bands[[2]int{i, j}] = rangeIndex(RangeLong)
+			}
+		}
+	}
+	return ranges
+}
+
+// between returns the range band between two fleets.
+func (r *battleRanges) between(i, j int) string {
+	if i > j {
+		i, j = j, i
+	}
+	return rangeBands[r.bands[[2]int{i, j}]]
+}
+
+// manoeuvre moves every pair of fleets towards their preferred ranges. When
+// the two disagree, the faster fleet gets its way; at equal speed the fleet
+// trying to close does. Stationary fleets have no speed.
+func (r *battleRanges) manoeuvre() []RangeChange {
+	changes := []RangeChange{}
+	
+	for i := range r.fleets {
+		for j := i + 1; j < len(r.fleets); j++ {
+			pair := [2]int{i, j}
+			band, exists := r.bands[pair]
+			if !exists || r.gone(i) || r.gone(j) {
+				continue
+			}
+			
+			wantI := r.want(i, band)
+			wantJ := r.want(j, band)
+			move := wantI
+			if wantI != wantJ {
+				speedI, speedJ := r.fleets[i].Speed(), r.fleets[j].Speed()
+				switch {
+				case speedI > speedJ:
+					move = wantI
+				case speedJ > speedI:
+					move = wantJ
+				case wantI < 0 || wantJ < 0:
+					move = -1
+				default:
+					move = 0
+				}
+			}
+			if move == 0 {
+				continue
+			}
+			
+			r.bands[pair] = band + move
+			changes = append(changes, RangeChange{
+				Fleets: []string{r.fleets[i].ID, r.fleets[j].ID},
+				From:   rangeBands[band],
+				To:     rangeBands[band+move],
+			})
+		}
+	}
+	return changes
+}
+
+// want is the way a fleet would like to move from a range band: -1 to
+// close, 1 to open it or 0 to hold. Stationary fleets always hold.
+func (r *battleRanges) want(i, band int) int {
+	if r.fleets[i].Stationary {
+		return 0
+	}
+	return direction(band, r.preferred[i])
+}
+
+// withdraw takes a fleet that has left the battle out of manoeuvring.
+func (r *battleRanges) withdraw(i int) {
+	r.withdrawn[i] = true
+}
+
+func (r *battleRanges) gone(i int) bool {
+	return r.withdrawn[i] || r.fleets[i].IsDefeated()
+}
+
+func direction(from, to int) int {
+	switch {
+	case to < from:
+		return -1
+	case to > from:
+		return 1
+	}
+	return 0
+}
+
+// preferredRange is the range band a fleet tries to fight at: the one set
+// by its owner, or otherwise the furthest one where its weapons do the most
+// damage.
+func preferredRange(fleet Fleet) int {
+	if index := rangeIndex(fleet.PreferredRange); index >= 0 {
+		return index
+	}
+	
+	best, bestDamage := rangeIndex(RangeLong), 0
+	for i, band := range rangeBands {
+		damage := 0
+		for _, ship := range fleet.GetAliveShips() {
+			for _, weapon := range ship.WeaponList() {
+				if weaponTypes[weapon.Type].reaches(band) {
+					damage += weapon.Damage
+				}
+			}
+		}
+		if damage >= bestDamage {
+			best, bestDamage = i, damage
+		}
+	}
+	return best
+}
+
+func (gs *GameState) processRangeOrder(order Order) {
+	fleetID, _ := order.Parameters["fleet_id"].(string)
+	band, _ := order.Parameters["range"].(string)
+	fleet := gs.findFleet(fleetID)
+	if fleet == nil || fleet.Owner != order.PlayerID {
+		return
+	}
+	if band != "" && rangeIndex(band) < 0 {
+		return
+	}
+	
+	fleet.PreferredRange = band
+}
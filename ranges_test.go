@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func armedFleet(id, owner, weapon string, speed int) Fleet {
+	ship := NewSpaceship(id+"_ship", "Ship", owner, 100, 0, 0, 0, speed)
+	ship.Weapons = []Weapon{{Type: weapon, Damage: 10}}
+	return testFleet(id, owner, ship)
+}
+
+func TestPreferredRange(t *testing.T) {
+	ordered := armedFleet("ordered", "a", "kinetic", 5)
+	ordered.PreferredRange = RangeShort
+	
+	tests := []struct {
+		fleet Fleet
+		want  string
+	}{
+		{armedFleet("guns", "a", "kinetic", 5), RangeMedium},
+		{armedFleet("missiles", "a", "missile", 5), RangeLong},
+		{armedFleet("lasers", "a", "energy", 5), RangeLong},
+		{ordered, RangeShort},
+		{testFleet("unarmed", "a"), RangeLong},
+	}
+	
+	for _, test := range tests {
+		if got := rangeBands[preferredRange(test.fleet)]; got != test.want {
+			t.Errorf("%s prefers %s range, want %s", test.fleet.ID, got, test.want)
+		}
+	}
+}
+
+func TestManoeuvre(t *testing.T) {
+	tests := []struct {
+		name        string
+		closerSpeed int
+		holderSpeed int
+		want        string
+	}{
+		{"faster fleet closes in", 6, 5, RangeMedium},
+		{"faster fleet holds the range", 5, 6, RangeLong},
+		{"closing wins at equal speed", 5, 5, RangeMedium},
+	}
+	
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fleets := []Fleet{
+				armedFleet("closer", "a", "kinetic", test.closerSpeed),
+				armedFleet("holder", "b", "missile", test.holderSpeed),
+			}
+			ranges := newBattleRanges(fleets, func(playerA, playerB string) bool { return playerA != playerB })
+			
+			changes := ranges.manoeuvre()
+			
+			if got := ranges.between(1, 0); got != test.want {
+				t.Errorf("range = %s, want %s", got, test.want)
+			}
+			if moved := len(changes) == 1; moved != (test.want != RangeLong) {
+				t.Errorf("changes = %+v", changes)
+			}
+		})
+	}
+}
+
+func TestBattleWithRangesOnlyFiresInReach(t *testing.T) {
+	fleets := []Fleet{
+		armedFleet("guns", "a", "kinetic", 5),
+		armedFleet("missiles", "b", "missile", 6),
+	}
+	
+	result := RunBattle(fleets, BattleConfig{Rand: rand.New(alwaysHits{}), Ranges: true, MaxRounds: 1})
+	
+	// The faster missile boat keeps the fight at long range, out of the
+	// mass drivers' reach
+	attacks := result.Rounds[0].Attacks
+	if len(attacks) != 1 || attacks[0].Attacker != "missiles_ship" || attacks[0].Range != RangeLong {
+		t.Errorf("attacks = %+v, want only the missile at long range", attacks)
+	}
+	if len(result.Rounds[0].RangeChanges) != 0 {
+		t.Errorf("range changed: %+v", result.Rounds[0].RangeChanges)
+	}
+}
+
+func TestRangeOrder(t *testing.T) {
+	gs := &GameState{Fleets: []Fleet{testFleet("fleet_1", "a")}}
+	for _, band := range []string{"point-blank", RangeShort, ""} {
+		gs.processRangeOrder(Order{PlayerID: "a", Parameters: map[string]interface{}{"fleet_id": "fleet_1", "range": band}})
+		if band == RangeShort && gs.Fleets[0].PreferredRange != RangeShort {
+			t.Errorf("preferred range = %q, want short", gs.Fleets[0].PreferredRange)
+		}
+	}
+	if gs.Fleets[0].PreferredRange != "" {
+		t.Errorf("an empty range didn't clear the preference")
+	}
+}
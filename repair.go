@@ -0,0 +1,66 @@
+package main
+
+import "fmt"
+
+const (
+	RepairNone       = "none"
+	RepairInProgress = "repairing"
+	RepairDocked     = "docked"
+	RepairNoSupply   = "out of supply"
+	RepairHostile    = "hostile territory"
+)
+
+// updateRepairs recharges shields on every ship and repairs hull on fleets
+// sitting in friendly systems inside their supply network. Fleets at a
+// system where their owner has a Factory or Shipyard repair faster.
+func (gs *GameState) updateRepairs() {
+	fmt.Println("Updating repairs...")
+	
+	for i := range gs.Fleets {
+		fleet := &gs.Fleets[i]
+		for j := range fleet.Ships {
+			fleet.Ships[j].RechargeShields(gs.Rules.ShieldRegenRate)
+		}
+		
+		fleet.RepairStatus = gs.repairStatus(fleet)
+		rate := 0
+		switch fleet.RepairStatus {
+		case RepairInProgress:
+			rate = gs.Rules.RepairRate
+		case RepairDocked:
+			rate = gs.Rules.ShipyardRepairRate
+		}
+		
+		for j := range fleet.Ships {
+			fleet.Ships[j].Repair(rate)
+		}
+	}
+}
+
+func (gs *GameState) repairStatus(fleet *Fleet) string {
+	damaged := false
+	for _, ship := range fleet.GetAliveShips() {
+		if ship.Hull < ship.MaxHull {
+			damaged = true
+		}
+	}
+	if !damaged {
+		return RepairNone
+	}
+	
+	if fleet.InTransit() || !fleet.InSupply {
+		return RepairNoSupply
+	}
+	
+	system := gs.Galaxy.GetSystemByID(fleet.Location)
+	if system == nil || system.ControlledBy == "" || gs.areHostile(system.ControlledBy, fleet.Owner) {
+		return RepairHostile
+	}
+	
+	for _, planet := range system.GetPlanetsByOwner(fleet.Owner) {
+		if planet.HasFacility("Factory") || planet.HasFacility("Shipyard") {
+			return RepairDocked
+		}
+	}
+	return RepairInProgress
+}
@@ -0,0 +1,223 @@
+package main
+
+import "html/template"
+
+// replayTemplate renders a battle as a standalone HTML page that plays it
+// back round by round. The battle's details are embedded in the page, so it
+// can be saved and opened without the server.
+var replayTemplate = template.Must(template.New("replay").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Battle {{.id}} at {{.system_name}}</title>
+<style>
+body { background: #0b0e1a; color: #d8dce8; font-family: sans-serif; margin: 20px; }
+h1 { font-size: 20px; margin: 0 0 4px 0; }
+.sides { display: flex; gap: 24px; margin: 16px 0; }
+.side { flex: 1; }
+.fleet { border: 1px solid #2a3150; border-radius: 4px; padding: 8px; margin-bottom: 12px; }
+.fleet h3 { font-size: 14px; margin: 0 0 8px 0; }
+.ship { padding: 4px; margin-bottom: 4px; border-radius: 3px; transition: background 0.2s; }
+.ship.attacking { background: #3a3520; }
+.ship.targeted { background: #4a2028; }
+.ship.destroyed { opacity: 0.3; }
+.ship .name { font-size: 12px; }
+.bar { height: 6px; background: #1c2035; margin-top: 2px; }
+.bar div { height: 100%; transition: width 0.4s; }
+.hull div { background: #4caf50; }
+.shields div { background: #42a5f5; }
+.controls { margin: 12px 0; }
+button { background: #2a3150; color: #d8dce8; border: none; padding: 6px 12px; margin-right: 4px; cursor: pointer; }
+#log { height: 200px; overflow-y: auto; background: #121629; padding: 8px; font-size: 12px; font-family: monospace; }
+#log .round { color: #ffd54f; margin-top: 6px; }
+#log .miss { color: #7a8099; }
+</style>
+</head>
+<body>
+<h1>Battle at {{.system_name}}, turn {{.turn}}</h1>
+<div>Winner: {{.winner}}, after {{.round_count}} rounds</div>
+<div class="controls">
+<button id="play">Play</button>
+<button id="step">Step</button>
+<button id="restart">Restart</button>
+<span id="status"></span>
+</div>
+<div class="sides" id="sides"></div>
+<div id="log"></div>
+<script>
+var battle = {{.}};
+
+var ships = {};
+var elements = {};
+var sides = document.getElementById("sides");
+var logBox = document.getElementById("log");
+var statusText = document.getElementById("status");
+
+function sideOf(owner) {
+	for (var i = 0; i < battle.sides.length; i++) {
+		if (battle.sides[i].indexOf(owner) >= 0) {
+			return i;
+		}
+	}
+	return 0;
+}
+
+function bar(kind) {
+	var outer = document.createElement("div");
+	outer.className = "bar " + kind;
+	outer.appendChild(document.createElement("div"));
+	return outer;
+}
+
+function build() {
+	sides.innerHTML = "";
+	logBox.innerHTML = "";
+	var columns = battle.sides.map(function() {
+		var column = document.createElement("div");
+		column.className = "side";
+		sides.appendChild(column);
+		return column;
+	});
+	battle.fleets.forEach(function(fleet) {
+		var box = document.createElement("div");
+		box.className = "fleet";
+		var title = document.createElement("h3");
+		title.textContent = fleet.name + " (" + fleet.owner + ")";
+		box.appendChild(title);
+		fleet.ships.forEach(function(ship) {
+			ships[ship.id] = {name: ship.name, maxHull: ship.max_hull, maxShields: ship.max_shields};
+			var element = document.createElement("div");
+			element.className = "ship";
+			var name = document.createElement("div");
+			name.className = "name";
+			name.textContent = ship.name + " [" + ship.class + "]";
+			element.appendChild(name);
+			element.appendChild(bar("hull"));
+			element.appendChild(bar("shields"));
+			box.appendChild(element);
+			elements[ship.id] = element;
+			show({id: ship.id, hull: ship.hull, shields: ship.shields, destroyed: false});
+		});
+		columns[sideOf(fleet.owner)].appendChild(box);
+	});
+}
+
+function percent(value, max) {
+	return max > 0 ? Math.max(0, Math.min(100, 100 * value / max)) : 0;
+}
+
+function show(state) {
+	var ship = ships[state.id];
+	var element = elements[state.id];
+	element.querySelector(".hull div").style.width = percent(state.hull, ship.maxHull) + "%";
+	element.querySelector(".shields div").style.width = percent(state.shields, ship.maxShields) + "%";
+	element.title = "Hull " + state.hull + "/" + ship.maxHull + ", shields " + state.shields + "/" + ship.maxShields;
+	element.classList.toggle("destroyed", state.destroyed);
+}
+
+function write(text, className) {
+	var line = document.createElement("div");
+	line.textContent = text;
+	if (className) {
+		line.className = className;
+	}
+	logBox.appendChild(line);
+	logBox.scrollTop = logBox.scrollHeight;
+}
+
+function shipName(id) {
+	return ships[id] ? ships[id].name : id;
+}
+
+function describe(attack) {
+	var text = shipName(attack.attacker) + " fires " + attack.weapon + " at " + shipName(attack.target);
+	if (attack.range) {
+		text += " (" + attack.range + " range)";
+	}
+	if (attack.pursuit) {
+		text += " in pursuit";
+	}
+	if (attack.intercepted) {
+		return text + ": intercepted";
+	}
+	return text + (attack.hit ? ": hit for " + attack.damage : ": miss");
+}
+
+// The replay walks through a list of steps: each attack in turn, then the
+// state of every ship at the end of the round.
+var steps = [];
+battle.rounds.forEach(function(round) {
+	steps.push({round: round});
+	round.attacks.forEach(function(attack) {
+		steps.push({attack: attack});
+	});
+	steps.push({end: round});
+});
+
+var position = 0;
+var timer = null;
+
+function clearHighlights() {
+	Object.keys(elements).forEach(function(id) {
+		elements[id].classList.remove("attacking", "targeted");
+	});
+}
+
+function step() {
+	if (position >= steps.length) {
+		stop();
+		statusText.textContent = "Finished";
+		return;
+	}
+	var current = steps[position++];
+	clearHighlights();
+	if (current.round) {
+		write("Round " + current.round.round, "round");
+		current.round.range_changes.forEach(function(change) {
+			write(change.fleets.join(" and ") + " move from " + change.from + " to " + change.to + " range");
+		});
+		statusText.textContent = "Round " + current.round.round + " of " + battle.rounds.length;
+	} else if (current.attack) {
+		elements[current.attack.attacker].classList.add("attacking");
+		elements[current.attack.target].classList.add("targeted");
+		write(describe(current.attack), current.attack.hit ? "" : "miss");
+	} else {
+		current.end.ships.forEach(show);
+		battle.retreats.forEach(function(retreat) {
+			if (retreat.round == current.end.round) {
+				write(retreat.fleet + " retreats (" + retreat.reason + ")");
+			}
+		});
+	}
+}
+
+function stop() {
+	clearInterval(timer);
+	timer = null;
+	document.getElementById("play").textContent = "Play";
+}
+
+document.getElementById("play").onclick = function() {
+	if (timer) {
+		stop();
+		return;
+	}
+	timer = setInterval(step, 300);
+	this.textContent = "Pause";
+};
+document.getElementById("step").onclick = function() {
+	stop();
+	step();
+};
+document.getElementById("restart").onclick = function() {
+	stop();
+	position = 0;
+	build();
+	statusText.textContent = "";
+};
+
+build();
+</script>
+</body>
+</html>
+`))
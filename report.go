@@ -0,0 +1,16 @@
+package main
+
+import "fmt"
+
+// report adds a line to a player's report for the turn being processed.
+func (gs *GameState) report(playerID, format string, args ...interface{}) {
+	if playerID == "" {
+		return
+	}
+	gs.Reports[playerID] = append(gs.Reports[playerID], fmt.Sprintf(format, args...))
+}
+
+// GetReport returns what happened to a player in the last processed turn.
+func (gs *GameState) GetReport(playerID string) []string {
+	return gs.Reports[playerID]
+}
@@ -0,0 +1,107 @@
+package main
+
+import "fmt"
+
+type Technology struct {
+	ID            string
+	Name          string
+	Cost          int
+	Prerequisites []string
+}
+
+var technologies = map[string]Technology{
+	"Terraforming": {
+		ID:   "Terraforming",
+		Name: "Terraforming",
+		Cost: 100,
+	},
+	"AdvancedTerraforming": {
+		ID:            "AdvancedTerraforming",
+		Name:          "Advanced Terraforming",
+		Cost:          250,
+		Prerequisites: []string{"Terraforming"},
+	},
+	"ImprovedHulls": {
+		ID:   "ImprovedHulls",
+		Name: "Improved Hulls",
+		Cost: 80,
+	},
+	"CapitalShips": {
+		ID:            "CapitalShips",
+		Name:          "Capital Ships",
+		Cost:          200,
+		Prerequisites: []string{"ImprovedHulls"},
+	},
+	"Lasers": {
+		ID:   "Lasers",
+		Name: "Lasers",
+		Cost: 60,
+	},
+	"DeflectorShields": {
+		ID:            "DeflectorShields",
+		Name:          "Deflector Shields",
+		Cost:          120,
+		Prerequisites: []string{"Lasers"},
+	},
+	"Missiles": {
+		ID:   "Missiles",
+		Name: "Missiles",
+		Cost: 70,
+	},
+	"Carriers": {
+		ID:            "Carriers",
+		Name:          "Carriers",
+		Cost:          150,
+		Prerequisites: []string{"ImprovedHulls"},
+	},
+}
+
+func (gs *GameState) HasTechnology(playerID, techID string) bool {
+	return gs.Research[playerID][techID]
+}
+
+// processResearchOrder unlocks a technology, paying for it with the
+// Technology stockpile of the given planet.
+func (gs *GameState) processResearchOrder(order Order) {
+	planet := gs.findPlanet(order.PlanetID)
+	if planet == nil || planet.Owner != order.PlayerID {
+		return
+	}
+	
+	techID, ok := order.Parameters["technology"].(string)
+	if !ok {
+		return
+	}
+	
+	tech, exists := technologies[techID]
+	if !exists || gs.HasTechnology(order.PlayerID, techID) {
+		return
+	}
+	
+	for _, prerequisite := range tech.Prerequisites {
+		if !gs.HasTechnology(order.PlayerID, prerequisite) {
+			return
+		}
+	}
+	
+	if planet.Resources.Technology < tech.Cost {
+		return
+	}
+	planet.Resources.Technology -= tech.Cost
+	
+	if gs.Research[order.PlayerID] == nil {
+		gs.Research[order.PlayerID] = make(map[string]bool)
+	}
+	gs.Research[order.PlayerID][techID] = true
+	fmt.Printf("Player %s researched %s\n", order.PlayerID, tech.Name)
+}
+
+func (gs *GameState) GetTechnologies(playerID string) []string {
+	var techs []string
+	for techID, known := range gs.Research[playerID] {
+		if known {
+			techs = append(techs, techID)
+		}
+	}
+	return techs
+}
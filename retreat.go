@@ -0,0 +1,177 @@
+package main
+
+import "fmt"
+
+const (
+	maxMorale         = 100
+	moralePerShipLost = 10
+	moralePerKill     = 5
+)
+
+const (
+	RetreatThreshold = "threshold"
+	RetreatMorale    = "morale"
+)
+
+// Retreat records a fleet leaving a battle before it was over.
+type Retreat struct {
+	Fleet  string
+	Player string
+	Round  int
+	Reason string
+}
+
+// battleMorale keeps track of how the fleets in a battle are holding up.
+// After every round a fleet loses morale for the share of its hull lost and
+// for every ship lost, and gains some back for every enemy ship it destroys.
+type battleMorale struct {
+	fleets    []Fleet
+	startHull []int
+	hull      []int
+	ships     []int
+	kills     []int
+}
+
+func newBattleMorale(fleets []Fleet) *battleMorale {
+	morale := &battleMorale{
+		fleets:    fleets,
+		startHull: make([]int, len(fleets)),
+		hull:      make([]int, len(fleets)),
+		ships:     make([]int, len(fleets)),
+		kills:     make([]int, len(fleets)),
+	}
+	for i := range fleets {
+		morale.hull[i] = fleetHull(fleets[i])
+		morale.startHull[i] = morale.hull[i]
+		morale.ships[i] = len(fleets[i].GetAliveShips())
+	}
+	return morale
+}
+
+func fleetHull(fleet Fleet) int {
+	hull := 0
+	for _, ship := range fleet.GetAliveShips() {
+		hull += ship.Hull
+	}
+	return hull
+}
+
+// endRound updates the morale of every fleet after a round of fire.
+func (m *battleMorale) endRound() {
+	for i := range m.fleets {
+		fleet := &m.fleets[i]
+		hull := fleetHull(*fleet)
+		ships := len(fleet.GetAliveShips())
+		
+		loss := 0
+		if m.startHull[i] > 0 {
+			loss = (m.hull[i] - hull) * 100 / m.startHull[i]
+		}
+		loss += (m.ships[i] - ships) * moralePerShipLost
+		fleet.Morale -= loss - m.kills[i]*moralePerKill
+		if fleet.Morale < 0 {
+			fleet.Morale = 0
+		}
+		if fleet.Morale > maxMorale {
+			fleet.Morale = maxMorale
+		}
+		
+		m.hull[i] = hull
+		m.ships[i] = ships
+		m.kills[i] = 0
+	}
+}
+
+// retreatReason says why a fleet wants to leave the battle, or returns an
+// empty string if it fights on.
+func (m *battleMorale) retreatReason(i int) string {
+	fleet := m.fleets[i]
+	if fleet.IsDefeated() || fleet.Stationary {
+		return ""
+	}
+	if fleet.RetreatThreshold > 0 && m.startHull[i] > 0 &&
+		m.hull[i]*100 <= m.startHull[i]*fleet.RetreatThreshold {
+		return RetreatThreshold
+	}
+	if fleet.Morale <= 0 {
+		return RetreatMorale
+	}
+	return ""
+}
+
+// retreatDestination picks an adjacent system the fleet can fall back to:
+// one controlled by its owner or an ally, with no hostile fleets in it.
+func (gs *GameState) retreatDestination(fleet Fleet) string {
+	system := gs.Galaxy.GetSystemByID(fleet.Location)
+	if system == nil {
+		return ""
+	}
+	
+	for _, neighbourID := range system.Connections {
+		neighbour := gs.Galaxy.GetSystemByID(neighbourID)
+		if neighbour == nil || neighbour.ControlledBy == "" {
+			continue
+		}
+		if neighbour.ControlledBy != fleet.Owner && !gs.areAllied(neighbour.ControlledBy, fleet.Owner) {
+			continue
+		}
+		if gs.hasHostileFleet(neighbourID, fleet.Owner) {
+			continue
+		}
+		return neighbourID
+	}
+	return ""
+}
+
+// retreatFleet moves a fleet that fled a battle to a friendly neighbouring
+// system and drops whatever it was doing.
+func (gs *GameState) retreatFleet(retreat Retreat) {
+	fleet := gs.findFleet(retreat.Fleet)
+	if fleet == nil || fleet.IsDefeated() {
+		return
+	}
+	destination := gs.retreatDestination(*fleet)
+	if destination == "" {
+		return
+	}
+	
+	from := gs.Galaxy.GetSystemByID(fleet.Location)
+	to := gs.Galaxy.GetSystemByID(destination)
+	fleet.Location = destination
+	fleet.Destination = ""
+	fleet.TurnsRemaining = 0
+	fleet.Route = nil
+	fleet.Patrol = nil
+	fleet.BombardTarget = ""
+	
+	fmt.Printf("Fleet %s retreated from %s to %s\n", fleet.ID, from.Name, to.Name)
+	gs.report(fleet.Owner, "Fleet %s retreated from %s to %s (%s)", fleet.ID, from.Name, to.Name, retreat.Reason)
+}
+
+// updateMorale lets fleets inside their supply network recover morale.
+func (gs *GameState) updateMorale() {
+	for i := range gs.Fleets {
+		fleet := &gs.Fleets[i]
+		if !fleet.InSupply {
+			continue
+		}
+		fleet.Morale += gs.Rules.MoraleRecovery
+		if fleet.Morale > maxMorale {
+			fleet.Morale = maxMorale
+		}
+	}
+}
+
+func (gs *GameState) processRetreatOrder(order Order) {
+	fleetID, _ := order.Parameters["fleet_id"].(string)
+	threshold, ok := order.Parameters["threshold"].(float64)
+	fleet := gs.findFleet(fleetID)
+	if fleet == nil || fleet.Owner != order.PlayerID {
+		return
+	}
+	if !ok || threshold < 0 || threshold > 100 {
+		return
+	}
+	
+	fleet.RetreatThreshold = int(threshold)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRunBattleRetreat(t *testing.T) {
+	tests := []struct {
+		name          string
+		attackerSpeed int
+		threshold     int
+		morale        int
+		wantReason    string
+		wantPursuits  int
+		wantHull      int
+	}{
+		{
+			name:          "threshold reached, slower enemy can't pursue",
+			attackerSpeed: 3,
+			threshold:     90,
+			morale:        maxMorale,
+			wantReason:    RetreatThreshold,
+			wantHull:      80,
+		},
+		{
+			name:          "threshold reached, faster enemy takes a parting shot",
+			attackerSpeed: 7,
+			threshold:     90,
+			morale:        maxMorale,
+			wantReason:    RetreatThreshold,
+			wantPursuits:  1,
+			wantHull:      60,
+		},
+		{
+			name:          "morale breaks",
+			attackerSpeed: 3,
+			morale:        15,
+			wantReason:    RetreatMorale,
+			wantHull:      80,
+		},
+	}
+	
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defender := testFleet("fleet_b", "b", NewSpaceship("ship_b", "Target", "b", 100, 0, 0, 0, 5))
+			defender.RetreatThreshold = test.threshold
+			defender.Morale = test.morale
+			fleets := []Fleet{
+				testFleet("fleet_a", "a", NewSpaceship("ship_a", "Hunter", "a", 100, 0, 0, 20, test.attackerSpeed)),
+				defender,
+			}
+			result := RunBattle(fleets, BattleConfig{Rand: rand.New(alwaysHits{})})
+			
+			if len(result.Retreats) != 1 {
+				t.Fatalf("retreats = %v, want one", result.Retreats)
+			}
+			retreat := result.Retreats[0]
+			if retreat.Fleet != "fleet_b" || retreat.Round != 1 || retreat.Reason != test.wantReason {
+				t.Errorf("retreat = %+v, want fleet_b in round 1 for %s", retreat, test.wantReason)
+			}
+			
+			pursuits := 0
+			for _, round := range result.Rounds {
+				for _, attack := range round.Attacks {
+					if attack.Pursuit {
+						pursuits++
+					}
+				}
+			}
+			if pursuits != test.wantPursuits {
+				t.Errorf("%d parting shots, want %d", pursuits, test.wantPursuits)
+			}
+			if hull := result.Fleets[1].Ships[0].Hull; hull != test.wantHull {
+				t.Errorf("defender hull = %d, want %d", hull, test.wantHull)
+			}
+			if outcome := result.Factions[1].Outcome; outcome != OutcomeRetreat {
+				t.Errorf("defender outcome = %q, want %q", outcome, OutcomeRetreat)
+			}
+		})
+	}
+}
+
+func TestBattleMoraleEndRound(t *testing.T) {
+	fleets := []Fleet{
+		testFleet("fleet_a", "a", NewSpaceship("a1", "Ship", "a", 50, 0, 0, 0, 5), NewSpaceship("a2", "Ship", "a", 50, 0, 0, 0, 5)),
+		testFleet("fleet_b", "b", NewSpaceship("b1", "Ship", "b", 100, 0, 0, 0, 5)),
+	}
+	morale := newBattleMorale(fleets)
+	
+	// a loses a ship, half its hull in all, and b gets the kill
+	fleets[0].Ships[0].TakeDamage(50)
+	fleets[1].Morale = 90
+	morale.kills[1] = 1
+	morale.endRound()
+	
+	if got := fleets[0].Morale; got != maxMorale-50-moralePerShipLost {
+		t.Errorf("a's morale = %d, want %d", got, maxMorale-50-moralePerShipLost)
+	}
+	if got := fleets[1].Morale; got != 90+moralePerKill {
+		t.Errorf("b's morale = %d, want %d", got, 90+moralePerKill)
+	}
+}
+
+func TestRetreatFleet(t *testing.T) {
+	gs := &GameState{
+		Galaxy: newTestGalaxy(map[string]Coordinates{
+			"front": {}, "enemy": {X: 10}, "home": {X: -10},
+		}, [][2]string{{"front", "enemy"}, {"front", "home"}}),
+		Relations: make(map[string]string),
+		Reports:   make(map[string][]string),
+	}
+	gs.Galaxy.GetSystemByID("enemy").ControlledBy = "b"
+	gs.Galaxy.GetSystemByID("home").ControlledBy = "a"
+	fleet := testFleet("fleet_a", "a", NewSpaceship("a1", "Ship", "a", 50, 0, 0, 0, 5))
+	fleet.Location = "front"
+	fleet.Route = []string{"enemy"}
+	gs.Fleets = []Fleet{fleet}
+	
+	gs.retreatFleet(Retreat{Fleet: "fleet_a", Player: "a", Round: 2, Reason: RetreatMorale})
+	
+	got := gs.Fleets[0]
+	if got.Location != "home" || len(got.Route) != 0 {
+		t.Errorf("fleet at %s with route %v, want at home with no orders", got.Location, got.Route)
+	}
+	
+	// With an enemy fleet at home there is nowhere left to go
+	gs.Fleets = append(gs.Fleets, NewFleet("fleet_b", "b", "home", []Spaceship{NewSpaceship("b1", "Ship", "b", 50, 0, 0, 0, 5)}))
+	gs.Fleets[0].Location = "front"
+	if destination := gs.retreatDestination(gs.Fleets[0]); destination != "" {
+		t.Errorf("retreat destination = %q, want none", destination)
+	}
+}
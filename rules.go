@@ -0,0 +1,25 @@
+package main
+
+// GameRules holds the tunable parts of the game. Rates are percentages of
+// the ship's maximum value restored per turn. TacticalRanges turns on
+// engagement ranges in combat. MoraleRecovery is the morale regained per
+// turn by fleets in supply. CombatResolution is how shots in a battle round
+// take effect: sequential or simultaneous.
+type GameRules struct {
+	ShieldRegenRate    int
+	RepairRate         int
+	ShipyardRepairRate int
+	TacticalRanges     bool
+	MoraleRecovery     int
+	CombatResolution   string
+}
+
+func DefaultGameRules() GameRules {
+	return GameRules{
+		ShieldRegenRate:    50,
+		RepairRate:         10,
+		ShipyardRepairRate: 25,
+		MoraleRecovery:     10,
+		CombatResolution:   ResolutionSequential,
+	}
+}
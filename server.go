@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 )
@@ -29,6 +30,20 @@ type APIResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+type DesignRequest struct {
+	PlayerID   string   `json:"player_id"`
+	Name       string   `json:"name"`
+	Hull       string   `json:"hull"`
+	Components []string `json:"components"`
+}
+
+type SimulationRequest struct {
+	PlayerID string             `json:"player_id"`
+	Fleets   []FleetComposition `json:"fleets"`
+	Battles  int                `json:"battles"`
+	Seed     int64              `json:"seed"`
+}
+
 type OrderRequest struct {
 	PlayerID   string                 `json:"player_id"`
 	OrderType  string                 `json:"order_type"`
@@ -67,6 +82,12 @@ func (gs *GameServer) StartServer(port int) {
 	http.HandleFunc("/player/", gs.handlePlayerStatus)
 	http.HandleFunc("/connect", gs.handleConnect)
 	http.HandleFunc("/turn", gs.handleTurnControl)
+	http.HandleFunc("/market", gs.handleMarket)
+	http.HandleFunc("/route", gs.handleRoutePreview)
+	http.HandleFunc("/designs", gs.handleDesigns)
+	http.HandleFunc("/simulate/battle", gs.handleSimulateBattle)
+	http.HandleFunc("/battles", gs.handleBattles)
+	http.HandleFunc("/battles/", gs.handleBattles)
 	
 	fmt.Printf("Galaxy Game Server starting on port %d\n", port)
 	fmt.Printf("Turn duration: %v\n", gs.turnDuration)
@@ -89,6 +110,14 @@ Endpoints:
 - POST /connect          - Connect as a player
 - POST /orders           - Submit orders
 - POST /turn             - Manual turn control (admin)
+- GET  /market           - Market prices and open offers
+- GET  /route            - Preview a fleet route and its ETA
+- GET  /designs          - Ship designs, hulls and components
+- POST /designs          - Save a ship design
+- POST /simulate/battle  - Simulate a battle many times and get the odds
+- GET  /battles          - Battles fought so far
+- GET  /battles/{id}     - A battle, round by round
+- GET  /battles/{id}/replay - An animated HTML replay of a battle
 
 Game Status: ` + gs.getGameStatus()
 	
@@ -116,6 +145,7 @@ func (gs *GameServer) handleStatus(w http.ResponseWriter, r *http.Request) {
 		"total_players":     len(gs.gameState.Players),
 		"turn_duration":     gs.turnDuration.Seconds(),
 		"systems_count":     len(gs.gameState.Galaxy.StarSystems),
+		"rules":             gs.gameState.Rules,
 	}
 	
 	gs.sendJSON(w, APIResponse{Success: true, Data: status})
@@ -133,6 +163,7 @@ func (gs *GameServer) handleGameState(w http.ResponseWriter, r *http.Request) {
 		"winner":      gs.gameState.Winner,
 		"players":     gs.getPlayerSummaries(),
 		"systems":     gs.getSystemSummaries(),
+		"fleets":      gs.getFleetSummaries(gs.gameState.Fleets),
 	}
 	
 	gs.sendJSON(w, APIResponse{Success: true, Data: gameData})
@@ -198,6 +229,11 @@ func (gs *GameServer) handlePlayerStatus(w http.ResponseWriter, r *http.Request)
 		"player_id":     playerID,
 		"summary":       gs.gameState.GetPlayerSummary(playerID),
 		"systems":       gs.getPlayerSystems(playerID),
+		"fleets":        gs.getFleetSummaries(gs.gameState.GetFleetsByOwner(playerID)),
+		"trade_routes":  gs.getPlayerTradeRoutes(playerID),
+		"technologies":  gs.gameState.GetTechnologies(playerID),
+		"relations":     gs.gameState.GetRelations(playerID),
+		"turn_report":   gs.gameState.GetReport(playerID),
 		"current_turn":  gs.gameState.CurrentTurn,
 		"orders_count":  len(gs.gameState.Orders[playerID]),
 	}
@@ -205,6 +241,247 @@ func (gs *GameServer) handlePlayerStatus(w http.ResponseWriter, r *http.Request)
 	gs.sendJSON(w, APIResponse{Success: true, Data: playerData})
 }
 
+func (gs *GameServer) handleMarket(w http.ResponseWriter, r *http.Request) {
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+	
+	market := gs.gameState.Market
+	offers := make([]map[string]interface{}, len(market.Offers))
+	for i, offer := range market.Offers {
+		offers[i] = map[string]interface{}{
+			"id":          offer.ID,
+			"player_id":   offer.PlayerID,
+			"resource":    offer.Resource,
+			"side":        offer.Side,
+			"quantity":    offer.Quantity,
+			"limit_price": offer.LimitPrice,
+			"expires":     offer.Expires,
+		}
+	}
+	
+	marketData := map[string]interface{}{
+		"prices": market.Prices,
+		"supply": market.Supply,
+		"demand": market.Demand,
+		"offers": offers,
+	}
+	
+	gs.sendJSON(w, APIResponse{Success: true, Data: marketData})
+}
+
+func (gs *GameServer) handleRoutePreview(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+	
+	// Only the fleet's owner may see where it could go
+	fleet := gs.gameState.findFleet(query.Get("fleet_id"))
+	if fleet == nil || fleet.Owner != query.Get("player_id") {
+		gs.sendJSON(w, APIResponse{Success: false, Message: "Fleet not found"})
+		return
+	}
+	
+	waypoints := []string{}
+	if list := query.Get("waypoints"); list != "" {
+		waypoints = strings.Split(list, ",")
+	}
+	if destination := query.Get("system_id"); destination != "" {
+		waypoints = append(waypoints, destination)
+	}
+	if len(waypoints) == 0 {
+		gs.sendJSON(w, APIResponse{Success: false, Message: "No destination given"})
+		return
+	}
+	
+	path := gs.gameState.planRoute(fleet, waypoints, query.Get("avoid_hostile") == "true")
+	if path == nil {
+		gs.sendJSON(w, APIResponse{Success: false, Message: "No route found"})
+		return
+	}
+	
+	routeData := map[string]interface{}{
+		"fleet_id": fleet.ID,
+		"path":     path,
+		"jumps":    len(path) - 1,
+		"eta":      gs.gameState.routeETA(fleet, path),
+	}
+	
+	gs.sendJSON(w, APIResponse{Success: true, Data: routeData})
+}
+
+func (gs *GameServer) handleDesigns(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "POST" {
+		gs.handleSaveDesign(w, r)
+		return
+	}
+	
+	playerID := r.URL.Query().Get("player_id")
+	
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+	
+	designs := gs.gameState.GetDesigns(playerID)
+	designData := make([]map[string]interface{}, len(designs))
+	for i, design := range designs {
+		stats := design.Stats()
+		designData[i] = map[string]interface{}{
+			"id":              design.ID,
+			"name":            design.Name,
+			"owner":           design.Owner,
+			"hull":            design.Hull,
+			"components":      design.Components,
+			"hull_points":     stats.Hull,
+			"armor":           stats.Armor,
+			"shields":         stats.Shields,
+			"attack":          stats.Attack,
+			"weapons":         stats.Weapons,
+			"point_defense":   stats.PointDefense,
+			"speed":           stats.Speed,
+			"cargo":           stats.Cargo,
+			"colony_capacity": stats.ColonyCapacity,
+			"troops":          stats.Troops,
+			"cost":            stats.Cost,
+			"buildable":       gs.gameState.CanBuildDesign(playerID, design),
+		}
+	}
+	
+	gs.sendJSON(w, APIResponse{Success: true, Data: map[string]interface{}{
+		"designs":    designData,
+		"hulls":        hullTypes,
+		"components":   components,
+		"weapon_types": weaponTypes,
+		"structures":   structureTypes,
+	}})
+}
+
+func (gs *GameServer) handleSaveDesign(w http.ResponseWriter, r *http.Request) {
+	var designReq DesignRequest
+	if err := json.NewDecoder(r.Body).Decode(&designReq); err != nil {
+		gs.sendJSON(w, APIResponse{Success: false, Message: "Invalid JSON"})
+		return
+	}
+	
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+	
+	if _, exists := gs.clients[designReq.PlayerID]; !exists {
+		gs.sendJSON(w, APIResponse{Success: false, Message: "Invalid player ID"})
+		return
+	}
+	
+	design, err := gs.gameState.SaveDesign(designReq.PlayerID, designReq.Name, designReq.Hull, designReq.Components)
+	if err != nil {
+		gs.sendJSON(w, APIResponse{Success: false, Message: err.Error()})
+		return
+	}
+	
+	gs.sendJSON(w, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Design %s saved", design.ID),
+		Data:    map[string]string{"design_id": design.ID},
+	})
+}
+
+func (gs *GameServer) handleSimulateBattle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		gs.sendJSON(w, APIResponse{Success: false, Message: "Method not allowed"})
+		return
+	}
+	
+	var simulationReq SimulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&simulationReq); err != nil {
+		gs.sendJSON(w, APIResponse{Success: false, Message: "Invalid JSON"})
+		return
+	}
+	
+	battles := simulationReq.Battles
+	if battles == 0 {
+		battles = defaultSimulatedBattles
+	}
+	if battles < 1 || battles > maxSimulatedBattles {
+		gs.sendJSON(w, APIResponse{Success: false, Message: fmt.Sprintf("battles must be between 1 and %d", maxSimulatedBattles)})
+		return
+	}
+	seed := simulationReq.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	
+	// The caller's own designs can be used in their fleets; every other
+	// fleet is limited to stock designs. The lock is only needed to look
+	// designs up, not for the battles themselves
+	gs.mutex.RLock()
+	if _, exists := gs.clients[simulationReq.PlayerID]; !exists {
+		gs.mutex.RUnlock()
+		gs.sendJSON(w, APIResponse{Success: false, Message: "Invalid player ID"})
+		return
+	}
+	lookup := func(designID, owner string) (ShipDesign, bool) {
+		if owner == simulationReq.PlayerID {
+			return gs.gameState.findDesign(designID, owner)
+		}
+		design, exists := stockDesigns[designID]
+		return design, exists
+	}
+	fleets, err := buildSimulatedFleets(simulationReq.Fleets, lookup)
+	config := BattleConfig{
+		Ranges:     gs.gameState.Rules.TacticalRanges,
+		Resolution: gs.gameState.Rules.CombatResolution,
+	}
+	gs.mutex.RUnlock()
+	if err != nil {
+		gs.sendJSON(w, APIResponse{Success: false, Message: err.Error()})
+		return
+	}
+	
+	result := SimulateBattle(fleets, battles, seed, config)
+	gs.sendJSON(w, APIResponse{Success: true, Data: map[string]interface{}{
+		"battles":         result.Battles,
+		"seed":            seed,
+		"win_probability": result.WinProbability,
+		"expected_losses": result.ExpectedLosses,
+		"rounds":          result.Rounds,
+		"average_rounds":  result.AverageRounds,
+	}})
+}
+
+func (gs *GameServer) handleBattles(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/battles"), "/")
+	
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+	
+	if path == "" {
+		battles := gs.gameState.GetBattles(r.URL.Query().Get("player_id"))
+		summaries := make([]map[string]interface{}, len(battles))
+		for i, battle := range battles {
+			summaries[i] = gs.getBattleSummary(battle)
+		}
+		gs.sendJSON(w, APIResponse{Success: true, Data: summaries})
+		return
+	}
+	
+	battleID, view, _ := strings.Cut(path, "/")
+	battle := gs.gameState.findBattle(battleID)
+	if battle == nil {
+		gs.sendJSON(w, APIResponse{Success: false, Message: "Battle not found"})
+		return
+	}
+	
+	switch view {
+	case "":
+		gs.sendJSON(w, APIResponse{Success: true, Data: gs.getBattleDetails(*battle)})
+	case "replay":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := replayTemplate.Execute(w, gs.getBattleDetails(*battle)); err != nil {
+			log.Printf("Error rendering replay of %s: %v", battle.ID, err)
+		}
+	default:
+		gs.sendJSON(w, APIResponse{Success: false, Message: "Unknown battle view"})
+	}
+}
+
 func (gs *GameServer) handleConnect(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		gs.sendJSON(w, APIResponse{Success: false, Message: "Method not allowed"})
@@ -324,12 +601,29 @@ func (gs *GameServer) getPlayerSummaries() map[string]string {
 func (gs *GameServer) getSystemSummaries() []map[string]interface{} {
 	systems := make([]map[string]interface{}, len(gs.gameState.Galaxy.StarSystems))
 	for i, system := range gs.gameState.Galaxy.StarSystems {
+		structures := make([]map[string]interface{}, len(system.Structures))
+		for j, structure := range system.Structures {
+			structures[j] = map[string]interface{}{
+				"id":          structure.ID,
+				"type":        structure.Type,
+				"owner":       structure.Owner,
+				"planet_id":   structure.PlanetID,
+				"hull":        structure.Hull,
+				"max_hull":    structure.MaxHull,
+				"shields":     structure.Shields,
+				"max_shields": structure.MaxShields,
+			}
+		}
+		
 		systems[i] = map[string]interface{}{
 			"id":           system.ID,
 			"name":         system.Name,
 			"controlled_by": system.ControlledBy,
+			"contested":    system.Contested,
 			"planet_count": len(system.Planets),
 			"coordinates":  system.Coordinates,
+			"connections":  system.Connections,
+			"structures":   structures,
 		}
 	}
 	return systems
@@ -351,3 +645,188 @@ func (gs *GameServer) getPlayerSystems(playerID string) []map[string]interface{}
 	return result
 }
 
+func (gs *GameServer) getPlayerTradeRoutes(playerID string) []map[string]interface{} {
+	routes := gs.gameState.GetTradeRoutesByOwner(playerID)
+	result := make([]map[string]interface{}, len(routes))
+	
+	for i, route := range routes {
+		result[i] = map[string]interface{}{
+			"id":        route.ID,
+			"from":      route.FromSystem,
+			"to":        route.ToSystem,
+			"income":    route.Income,
+			"disrupted": route.Disrupted,
+		}
+	}
+	return result
+}
+
+func (gs *GameServer) getBattleSummary(battle BattleRecord) map[string]interface{} {
+	systemName := battle.SystemID
+	if system := gs.gameState.Galaxy.GetSystemByID(battle.SystemID); system != nil {
+		systemName = system.Name
+	}
+	
+	factions := make([]map[string]interface{}, len(battle.Result.Factions))
+	for i, faction := range battle.Result.Factions {
+		factions[i] = map[string]interface{}{
+			"player":          faction.Player,
+			"side":            faction.Side,
+			"outcome":         faction.Outcome,
+			"ships_start":     faction.ShipsStart,
+			"ships_lost":      faction.ShipsLost,
+			"ships_destroyed": faction.ShipsDestroyed,
+		}
+	}
+	
+	return map[string]interface{}{
+		"id":          battle.ID,
+		"turn":        battle.Turn,
+		"system_id":   battle.SystemID,
+		"system_name": systemName,
+		"winner":      battle.Result.Winner,
+		"round_count": len(battle.Result.Rounds),
+		"sides":       battle.Result.Sides,
+		"factions":    factions,
+	}
+}
+
+// getBattleDetails is the full record of a battle: the fleets that fought,
+// every attack, and the state of every ship after each round.
+func (gs *GameServer) getBattleDetails(battle BattleRecord) map[string]interface{} {
+	details := gs.getBattleSummary(battle)
+	
+	fleets := make([]map[string]interface{}, len(battle.Result.Initial))
+	for i, fleet := range battle.Result.Initial {
+		ships := make([]map[string]interface{}, len(fleet.Ships))
+		for j, ship := range fleet.Ships {
+			ships[j] = map[string]interface{}{
+				"id":          ship.ID,
+				"name":        ship.Name,
+				"class":       ship.Class,
+				"hull":        ship.Hull,
+				"max_hull":    ship.MaxHull,
+				"shields":     ship.Shields,
+				"max_shields": ship.MaxShields,
+			}
+		}
+		fleets[i] = map[string]interface{}{
+			"id":    fleet.ID,
+			"name":  fleet.Name,
+			"owner": fleet.Owner,
+			"ships": ships,
+		}
+	}
+	
+	rounds := make([]map[string]interface{}, len(battle.Result.Rounds))
+	for i, round := range battle.Result.Rounds {
+		rangeChanges := make([]map[string]interface{}, len(round.RangeChanges))
+		for j, change := range round.RangeChanges {
+			rangeChanges[j] = map[string]interface{}{
+				"fleets": change.Fleets,
+				"from":   change.From,
+				"to":     change.To,
+			}
+		}
+		
+		attacks := make([]map[string]interface{}, len(round.Attacks))
+		for j, attack := range round.Attacks {
+			attacks[j] = map[string]interface{}{
+				"attacker":    attack.Attacker,
+				"target":      attack.Target,
+				"weapon":      attack.Weapon,
+				"hit":         attack.Hit,
+				"damage":      attack.Damage,
+				"intercepted": attack.Intercepted,
+				"pursuit":     attack.Pursuit,
+				"range":       attack.Range,
+				"rule":        attack.Rule,
+			}
+		}
+		
+		ships := make([]map[string]interface{}, len(round.Ships))
+		for j, ship := range round.Ships {
+			ships[j] = map[string]interface{}{
+				"id":        ship.ID,
+				"hull":      ship.Hull,
+				"shields":   ship.Shields,
+				"destroyed": ship.Destroyed,
+			}
+		}
+		
+		rounds[i] = map[string]interface{}{
+			"round":         round.RoundNumber,
+			"range_changes": rangeChanges,
+			"attacks":       attacks,
+			"ships":         ships,
+		}
+	}
+	
+	retreats := make([]map[string]interface{}, len(battle.Result.Retreats))
+	for i, retreat := range battle.Result.Retreats {
+		retreats[i] = map[string]interface{}{
+			"fleet":  retreat.Fleet,
+			"player": retreat.Player,
+			"round":  retreat.Round,
+			"reason": retreat.Reason,
+		}
+	}
+	
+	details["fleets"] = fleets
+	details["rounds"] = rounds
+	details["retreats"] = retreats
+	return details
+}
+
+func (gs *GameServer) getFleetSummaries(fleets []Fleet) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(fleets))
+	
+	for i, fleet := range fleets {
+		ships := make([]map[string]interface{}, len(fleet.Ships))
+		for j, ship := range fleet.Ships {
+			ships[j] = map[string]interface{}{
+				"id":            ship.ID,
+				"name":          ship.Name,
+				"class":         ship.Class,
+				"design_id":     ship.DesignID,
+				"hull":          ship.Hull,
+				"max_hull":      ship.MaxHull,
+				"shields":       ship.Shields,
+				"max_shields":   ship.MaxShields,
+				"armor":         ship.Armor,
+				"attack":        ship.Attack,
+				"weapons":       ship.WeaponList(),
+				"point_defense": ship.PointDefense,
+				"speed":         ship.Speed,
+				"troops":        ship.Troops,
+				"fuel":          ship.Fuel,
+				"max_fuel":      ship.MaxFuel,
+			}
+		}
+		
+		result[i] = map[string]interface{}{
+			"id":          fleet.ID,
+			"name":        fleet.Name,
+			"owner":       fleet.Owner,
+			"location":    fleet.Location,
+			"in_transit":  fleet.InTransit(),
+			"destination": fleet.Destination,
+			"eta":         fleet.TurnsRemaining,
+			"route":       fleet.Route,
+			"patrol":      fleet.Patrol,
+			"speed":       fleet.Speed(),
+			"in_supply":   fleet.InSupply,
+			"repair":      fleet.RepairStatus,
+			"bombarding":  fleet.BombardTarget,
+			"doctrine":    fleet.Doctrine,
+			"range":       fleet.PreferredRange,
+			"morale":      fleet.Morale,
+			"retreat_at":  fleet.RetreatThreshold,
+			"fuel":        fleet.Fuel(),
+			"ship_count":  len(fleet.Ships),
+			"ships":       ships,
+		}
+	}
+	return result
+}
+
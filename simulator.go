@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultSimulatedBattles = 1000
+	maxSimulatedBattles     = 10000
+	maxSimulatedShips       = 200
+)
+
+// FleetComposition describes a fleet for the battle simulator: how many
+// ships of each design it has, and how it fights.
+type FleetComposition struct {
+	Owner            string         `json:"owner"`
+	Ships            map[string]int `json:"ships"`
+	Doctrine         string         `json:"doctrine"`
+	Range            string         `json:"range"`
+	RetreatThreshold int            `json:"retreat_threshold"`
+}
+
+// SimulationResult sums up many runs of the same battle. WinProbability is
+// keyed by the battle's Winner, so allied players left standing together
+// are listed as one, and stalemates as "Draw". ExpectedLosses is the average number of
+// ships each player lost, and Rounds counts how many battles lasted each
+// number of rounds.
+type SimulationResult struct {
+	Battles        int
+	WinProbability map[string]float64
+	ExpectedLosses map[string]float64
+	Rounds         map[int]int
+	AverageRounds  float64
+}
+
+// parseFleetComposition reads a fleet written as owner=Design:count,...,
+// for example p1=Destroyer:3,Fighter:2. The count defaults to one.
+func parseFleetComposition(text string) (FleetComposition, error) {
+	owner, ships, found := strings.Cut(text, "=")
+	if !found || owner == "" || ships == "" {
+		return FleetComposition{}, fmt.Errorf("fleet %q should look like owner=Design:count,...", text)
+	}
+	
+	composition := FleetComposition{Owner: owner, Ships: make(map[string]int)}
+	for _, entry := range strings.Split(ships, ",") {
+		design, count, hasCount := strings.Cut(entry, ":")
+		n := 1
+		if hasCount {
+			var err error
+			if n, err = strconv.Atoi(count); err != nil {
+				return FleetComposition{}, fmt.Errorf("bad ship count %q in fleet %q", count, text)
+			}
+		}
+		composition.Ships[design] += n
+	}
+	return composition, nil
+}
+
+// buildSimulatedFleets turns fleet compositions into fleets, looking up
+// designs by ID and owner. The fleets may have at most maxSimulatedShips
+// ships between them.
+func buildSimulatedFleets(compositions []FleetComposition, lookup func(designID, owner string) (ShipDesign, bool)) ([]Fleet, error) {
+	owners := make(map[string]bool)
+	totalShips := 0
+	fleets := make([]Fleet, len(compositions))
+	for i, composition := range compositions {
+		if composition.Owner == "" {
+			return nil, fmt.Errorf("fleet %d has no owner", i+1)
+		}
+		if composition.Doctrine != "" {
+			if _, exists := targetingStrategies[composition.Doctrine]; !exists {
+				return nil, fmt.Errorf("unknown doctrine %q", composition.Doctrine)
+			}
+		}
+		if composition.Range != "" && rangeIndex(composition.Range) < 0 {
+			return nil, fmt.Errorf("unknown range %q", composition.Range)
+		}
+		if composition.RetreatThreshold < 0 || composition.RetreatThreshold > 100 {
+			return nil, fmt.Errorf("retreat threshold must be between 0 and 100")
+		}
+		owners[composition.Owner] = true
+		
+		designIDs := make([]string, 0, len(composition.Ships))
+		for designID := range composition.Ships {
+			designIDs = append(designIDs, designID)
+		}
+		sort.Strings(designIDs)
+		
+		var ships []Spaceship
+		for _, designID := range designIDs {
+			design, exists := lookup(designID, composition.Owner)
+			if !exists {
+				return nil, fmt.Errorf("unknown design %q", designID)
+			}
+			count := composition.Ships[designID]
+			if count < 0 {
+				return nil, fmt.Errorf("negative ship count for %s", designID)
+			}
+			if count > maxSimulatedShips-totalShips {
+				return nil, fmt.Errorf("the fleets have more than %d ships between them", maxSimulatedShips)
+			}
+			totalShips += count
+			for n := 1; n <= count; n++ {
+				id := fmt.Sprintf("sim_%d_%s_%d", i+1, designID, n)
+				ships = append(ships, NewSpaceshipFromDesign(id, composition.Owner, design))
+			}
+		}
+		if len(ships) == 0 {
+			return nil, fmt.Errorf("fleet %d has no ships", i+1)
+		}
+		
+		fleets[i] = NewFleet(fmt.Sprintf("sim_fleet_%d", i+1), composition.Owner, "", ships)
+		if composition.Doctrine != "" {
+			fleets[i].Doctrine = composition.Doctrine
+		}
+		fleets[i].PreferredRange = composition.Range
+		fleets[i].RetreatThreshold = composition.RetreatThreshold
+	}
+	
+	if len(owners) < 2 {
+		return nil, fmt.Errorf("a battle needs fleets of at least two owners")
+	}
+	return fleets, nil
+}
+
+// simulationTally adds up the battles fought by one simulator worker.
+type simulationTally struct {
+	wins        map[string]int
+	losses      map[string]int
+	rounds      map[int]int
+	totalRounds int
+}
+
+func newSimulationTally() *simulationTally {
+	return &simulationTally{
+		wins:   make(map[string]int),
+		losses: make(map[string]int),
+		rounds: make(map[int]int),
+	}
+}
+
+func (t *simulationTally) add(result BattleResult) {
+	t.wins[result.Winner]++
+	for _, faction := range result.Factions {
+		t.losses[faction.Player] += faction.ShipsLost
+	}
+	t.rounds[len(result.Rounds)]++
+	t.totalRounds += len(result.Rounds)
+}
+
+func (t *simulationTally) merge(other *simulationTally) {
+	for winner, count := range other.wins {
+		t.wins[winner] += count
+	}
+	for player, lost := range other.losses {
+		t.losses[player] += lost
+	}
+	for rounds, count := range other.rounds {
+		t.rounds[rounds] += count
+	}
+	t.totalRounds += other.totalRounds
+}
+
+// SimulateBattle fights the same battle many times in parallel, each with
+// its own seed counting up from seed, and sums up the results. Each worker
+// keeps a running tally rather than the battles themselves.
+func SimulateBattle(fleets []Fleet, battles int, seed int64, config BattleConfig) SimulationResult {
+	jobs := make(chan int)
+	tallies := make([]*simulationTally, runtime.NumCPU())
+	
+	var wg sync.WaitGroup
+	for w := range tallies {
+		tally := newSimulationTally()
+		tallies[w] = tally
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				battleConfig := config
+				battleConfig.Rand = rand.New(rand.NewSource(seed + int64(i)))
+				tally.add(RunBattle(fleets, battleConfig))
+			}
+		}()
+	}
+	for i := 0; i < battles; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	
+	total := newSimulationTally()
+	for _, tally := range tallies {
+		total.merge(tally)
+	}
+	
+	summary := SimulationResult{
+		Battles:        battles,
+		WinProbability: make(map[string]float64),
+		ExpectedLosses: make(map[string]float64),
+		Rounds:         total.rounds,
+	}
+	if battles == 0 {
+		return summary
+	}
+	for winner, count := range total.wins {
+		summary.WinProbability[winner] = float64(count) / float64(battles)
+	}
+	for player, lost := range total.losses {
+		summary.ExpectedLosses[player] = float64(lost) / float64(battles)
+	}
+	summary.AverageRounds = float64(total.totalRounds) / float64(battles)
+	return summary
+}
+
+func PrintSimulationResult(result SimulationResult) {
+	fmt.Printf("Simulated %d battles, lasting %.1f rounds on average\n", result.Battles, result.AverageRounds)
+	
+	fmt.Println("Win probability:")
+	for _, winner := range sortedKeys(result.WinProbability) {
+		fmt.Printf("  %s: %.1f%%\n", winner, result.WinProbability[winner]*100)
+	}
+	
+	fmt.Println("Expected losses:")
+	for _, player := range sortedKeys(result.ExpectedLosses) {
+		fmt.Printf("  %s: %.2f ships\n", player, result.ExpectedLosses[player])
+	}
+	
+	rounds := make([]int, 0, len(result.Rounds))
+	for n := range result.Rounds {
+		rounds = append(rounds, n)
+	}
+	sort.Ints(rounds)
+	fmt.Println("Rounds:")
+	for _, n := range rounds {
+		fmt.Printf("  %3d: %d\n", n, result.Rounds[n])
+	}
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
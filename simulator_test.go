@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func stockDesign(designID, owner string) (ShipDesign, bool) {
+	design, exists := stockDesigns[designID]
+	return design, exists
+}
+
+func TestParseFleetComposition(t *testing.T) {
+	got, err := parseFleetComposition("p1=Destroyer:3,Fighter,Destroyer:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := FleetComposition{Owner: "p1", Ships: map[string]int{"Destroyer": 5, "Fighter": 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("composition = %+v, want %+v", got, want)
+	}
+	
+	for _, text := range []string{"Destroyer:3", "=Destroyer", "p1=", "p1=Destroyer:many"} {
+		if _, err := parseFleetComposition(text); err == nil {
+			t.Errorf("parsed %q without an error", text)
+		}
+	}
+}
+
+func TestBuildSimulatedFleetsValidation(t *testing.T) {
+	fighters := map[string]int{"Fighter": 1}
+	tests := map[string][]FleetComposition{
+		"no owner":         {{Ships: fighters}, {Owner: "b", Ships: fighters}},
+		"one owner":        {{Owner: "a", Ships: fighters}, {Owner: "a", Ships: fighters}},
+		"unknown design":   {{Owner: "a", Ships: map[string]int{"Deathstar": 1}}, {Owner: "b", Ships: fighters}},
+		"no ships":         {{Owner: "a", Ships: map[string]int{"Fighter": 0}}, {Owner: "b", Ships: fighters}},
+		"negative count":   {{Owner: "a", Ships: map[string]int{"Fighter": -2}}, {Owner: "b", Ships: fighters}},
+		"unknown doctrine": {{Owner: "a", Ships: fighters, Doctrine: "kamikaze"}, {Owner: "b", Ships: fighters}},
+		"unknown range":    {{Owner: "a", Ships: fighters, Range: "orbit"}, {Owner: "b", Ships: fighters}},
+		"bad threshold":    {{Owner: "a", Ships: fighters, RetreatThreshold: 150}, {Owner: "b", Ships: fighters}},
+		"too many ships":   {{Owner: "a", Ships: map[string]int{"Fighter": 150}}, {Owner: "b", Ships: map[string]int{"Fighter": 51}}},
+		"huge count":       {{Owner: "a", Ships: map[string]int{"Fighter": math.MaxInt}}, {Owner: "b", Ships: fighters}},
+	}
+	
+	for name, compositions := range tests {
+		if _, err := buildSimulatedFleets(compositions, stockDesign); err == nil {
+			t.Errorf("%s: built fleets without an error", name)
+		}
+	}
+	
+	fleets, err := buildSimulatedFleets([]FleetComposition{
+		{Owner: "a", Ships: map[string]int{"Destroyer": 2, "Fighter": 1}, Doctrine: "weakest"},
+		{Owner: "b", Ships: fighters},
+	}, stockDesign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fleets[0].Ships) != 3 || fleets[0].Doctrine != "weakest" || fleets[1].Doctrine != defaultDoctrine {
+		t.Errorf("fleets = %+v", fleets)
+	}
+}
+
+func TestSimulateBattle(t *testing.T) {
+	fleets, err := buildSimulatedFleets([]FleetComposition{
+		{Owner: "a", Ships: map[string]int{"Destroyer": 2}},
+		{Owner: "b", Ships: map[string]int{"Fighter": 2}},
+	}, stockDesign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	result := SimulateBattle(fleets, 200, 42, BattleConfig{})
+	
+	total, battles := 0.0, 0
+	for _, probability := range result.WinProbability {
+		total += probability
+	}
+	for _, count := range result.Rounds {
+		battles += count
+	}
+	if result.Battles != 200 || battles != 200 {
+		t.Errorf("%d battles with %d round counts, want 200", result.Battles, battles)
+	}
+	if total < 0.999 || total > 1.001 {
+		t.Errorf("win probabilities add up to %v", total)
+	}
+	if result.WinProbability["a"] < 0.5 {
+		t.Errorf("destroyers won only %.0f%% against fighters", result.WinProbability["a"]*100)
+	}
+	
+	// The same seed fights the same battles
+	if again := SimulateBattle(fleets, 200, 42, BattleConfig{}); !reflect.DeepEqual(again, result) {
+		t.Errorf("rerun with the same seed = %+v, want %+v", again, result)
+	}
+}
+
+func TestSortedKeys(t *testing.T) {
+	keys := sortedKeys(map[string]float64{"c": 1, "Draw": 0, "a+b": 0})
+	if strings.Join(keys, " ") != "Draw a+b c" {
+		t.Errorf("sorted keys = %v", keys)
+	}
+}
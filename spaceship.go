@@ -1,24 +1,47 @@
 package main
 
 type Spaceship struct {
-	ID          string
-	Name        string
-	Owner       string
-	Hull        int
-	MaxHull     int
-	Armor       int
-	Shields     int
-	MaxShields  int
-	Attack      int
-	Speed       int
-	IsDestroyed bool
+	ID             string
+	Name           string
+	Owner          string
+	Hull           int
+	MaxHull        int
+	Armor          int
+	Shields        int
+	MaxShields     int
+	Attack         int
+	Weapons        []Weapon
+	PointDefense   int
+	Speed          int
+	Class          string
+	DesignID       string
+	Cargo          int
+	ColonyCapacity int
+	Troops         int
+	Fuel           int
+	MaxFuel        int
+	IsDestroyed    bool
 }
 
 type Fleet struct {
-	ID        string
-	Owner     string
-	Ships     []Spaceship
-	Location  string
+	ID               string
+	Name             string
+	Owner            string
+	Ships            []Spaceship
+	Location         string
+	Destination      string
+	TurnsRemaining   int
+	Route            []string
+	Patrol           []string
+	AvoidHostile     bool
+	InSupply         bool
+	RepairStatus     string
+	BombardTarget    string
+	Doctrine         string
+	PreferredRange   string
+	Morale           int
+	RetreatThreshold int
+	Stationary       bool
 }
 
 func NewSpaceship(id, name, owner string, hull, armor, shields, attack, speed int) Spaceship {
@@ -39,10 +62,14 @@ func NewSpaceship(id, name, owner string, hull, armor, shields, attack, speed in
 
 func NewFleet(id, owner, location string, ships []Spaceship) Fleet {
 	return Fleet{
-		ID:       id,
-		Owner:    owner,
-		Ships:    ships,
-		Location: location,
+		ID:           id,
+		Name:         id,
+		Owner:        owner,
+		Ships:        ships,
+		Location:     location,
+		RepairStatus: RepairNone,
+		Doctrine:     defaultDoctrine,
+		Morale:       maxMorale,
 	}
 }
 
@@ -71,6 +98,22 @@ func (s *Spaceship) TakeDamage(damage int) {
 	}
 }
 
+// RechargeShields restores a percentage of the ship's maximum shields.
+func (s *Spaceship) RechargeShields(percent int) {
+	if !s.IsAlive() {
+		return
+	}
+	s.Shields = min(s.MaxShields, s.Shields+max(1, s.MaxShields*percent/100))
+}
+
+// Repair restores a percentage of the ship's maximum hull.
+func (s *Spaceship) Repair(percent int) {
+	if !s.IsAlive() || percent <= 0 {
+		return
+	}
+	s.Hull = min(s.MaxHull, s.Hull+max(1, s.MaxHull*percent/100))
+}
+
 func (s *Spaceship) IsAlive() bool {
 	return !s.IsDestroyed && s.Hull > 0
 }
@@ -87,4 +130,94 @@ func (f *Fleet) GetAliveShips() []Spaceship {
 
 func (f *Fleet) IsDefeated() bool {
 	return len(f.GetAliveShips()) == 0
-}
\ No newline at end of file
+}
+
+func (f *Fleet) AddShip(ship Spaceship) {
+	f.Ships = append(f.Ships, ship)
+}
+
+// RemoveShips takes the ships with the given IDs out of the fleet and
+// returns them. It returns nil without changing the fleet unless every ID is
+// one of the fleet's ships.
+func (f *Fleet) RemoveShips(shipIDs []string) []Spaceship {
+	wanted := make(map[string]bool)
+	for _, id := range shipIDs {
+		wanted[id] = true
+	}
+	
+	var removed, kept []Spaceship
+	for _, ship := range f.Ships {
+		if wanted[ship.ID] {
+			removed = append(removed, ship)
+		} else {
+			kept = append(kept, ship)
+		}
+	}
+	if len(removed) == 0 || len(removed) != len(wanted) {
+		return nil
+	}
+	
+	f.Ships = kept
+	return removed
+}
+
+// InTransit reports whether the fleet is travelling between systems. While
+// it is, Location is the system it left and Destination the next system on
+// its route.
+func (f *Fleet) InTransit() bool {
+	return f.Destination != ""
+}
+
+// Speed is the speed of the fleet's slowest surviving ship.
+func (f *Fleet) Speed() int {
+	speed := 0
+	for _, ship := range f.GetAliveShips() {
+		if speed == 0 || ship.Speed < speed {
+			speed = ship.Speed
+		}
+	}
+	return speed
+}
+
+// Fuel is the fuel left in the emptiest tank among the fleet's surviving
+// ships.
+func (f *Fleet) Fuel() int {
+	fuel := -1
+	for _, ship := range f.GetAliveShips() {
+		if fuel < 0 || ship.Fuel < fuel {
+			fuel = ship.Fuel
+		}
+	}
+	return max(fuel, 0)
+}
+
+func (f *Fleet) Refuel() {
+	for i := range f.Ships {
+		f.Ships[i].Fuel = f.Ships[i].MaxFuel
+	}
+}
+
+// BurnFuel uses up fuel on every ship in the fleet. It returns false if any
+// surviving ship had run dry.
+func (f *Fleet) BurnFuel(amount int) bool {
+	fueled := true
+	for i := range f.Ships {
+		ship := &f.Ships[i]
+		if !ship.IsAlive() {
+			continue
+		}
+		if ship.Fuel == 0 {
+			fueled = false
+		}
+		ship.Fuel = max(0, ship.Fuel-amount)
+	}
+	return fueled
+}
+
+func (f *Fleet) TotalAttack() int {
+	attack := 0
+	for _, ship := range f.GetAliveShips() {
+		attack += ship.Attack
+	}
+	return attack
+}
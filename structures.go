@@ -0,0 +1,229 @@
+package main
+
+import "fmt"
+
+// StructureType describes a defence that can be built in a star system.
+// Structures don't move: they join every battle in their system as a
+// stationary fleet belonging to their owner.
+type StructureType struct {
+	Name         string
+	Hull         int
+	Shields      int
+	Armor        int
+	Weapons      []Weapon
+	PointDefense int
+	Cost         Resources
+	Limit        string
+}
+
+// How many of a structure can be built: one per planet, one per player in
+// each system, or as many as the player can pay for.
+const (
+	LimitPerPlanet = "planet"
+	LimitPerSystem = "system"
+	LimitNone      = ""
+)
+
+var structureTypes = map[string]StructureType{
+	"PlanetaryShield": {
+		Name: "PlanetaryShield", Hull: 100, Shields: 250, Armor: 2,
+		Cost:  Resources{Metals: 150, Energy: 150, Minerals: 50},
+		Limit: LimitPerPlanet,
+	},
+	"DefenceBattery": {
+		Name: "DefenceBattery", Hull: 150, Armor: 6,
+		Weapons:      []Weapon{{Type: "kinetic", Damage: 30}},
+		PointDefense: 2,
+		Cost:         Resources{Metals: 120, Energy: 40, Minerals: 40},
+		Limit:        LimitNone,
+	},
+	"Starbase": {
+		Name: "Starbase", Hull: 600, Shields: 150, Armor: 8,
+		Weapons:      []Weapon{{Type: "kinetic", Damage: 30}, {Type: "missile", Damage: 20}},
+		PointDefense: 4,
+		Cost:         Resources{Metals: 300, Energy: 150, Minerals: 150, Technology: 50},
+		Limit:        LimitPerSystem,
+	},
+}
+
+// Structure is a defence standing in a star system, built from one of the
+// owner's planets there.
+type Structure struct {
+	ID         string
+	Type       string
+	Owner      string
+	PlanetID   string
+	Hull       int
+	MaxHull    int
+	Shields    int
+	MaxShields int
+}
+
+// HasStructure reports whether the owner has a structure of the given type
+// in the system.
+func (s *StarSystem) HasStructure(owner, structureType string) bool {
+	for _, structure := range s.Structures {
+		if structure.Owner == owner && structure.Type == structureType {
+			return true
+		}
+	}
+	return false
+}
+
+// ship is the structure as it fights: a ship that never moves.
+func (s Structure) ship() Spaceship {
+	structureType := structureTypes[s.Type]
+	attack := 0
+	for _, weapon := range structureType.Weapons {
+		attack += weapon.Damage
+	}
+	return Spaceship{
+		ID:           s.ID,
+		Name:         s.Type,
+		Owner:        s.Owner,
+		Hull:         s.Hull,
+		MaxHull:      s.MaxHull,
+		Armor:        structureType.Armor,
+		Shields:      s.Shields,
+		MaxShields:   s.MaxShields,
+		Attack:       attack,
+		Weapons:      structureType.Weapons,
+		PointDefense: structureType.PointDefense,
+		Speed:        0,
+		Class:        s.Type,
+	}
+}
+
+// defenceFleets groups the structures in a system into one stationary fleet
+// per owner, ready to join a battle.
+func defenceFleets(system *StarSystem) []Fleet {
+	var owners []string
+	ships := make(map[string][]Spaceship)
+	for _, structure := range system.Structures {
+		if _, seen := ships[structure.Owner]; !seen {
+			owners = append(owners, structure.Owner)
+		}
+		ships[structure.Owner] = append(ships[structure.Owner], structure.ship())
+	}
+	
+	fleets := make([]Fleet, len(owners))
+	for i, owner := range owners {
+		fleets[i] = NewFleet(fmt.Sprintf("defences_%s_%s", system.ID, owner), owner, system.ID, ships[owner])
+		fleets[i].Name = system.Name + " defences"
+		fleets[i].Stationary = true
+	}
+	return fleets
+}
+
+// applyStructureDamage copies the state of structures after a battle back
+// onto the system, tearing down those that were destroyed.
+func (gs *GameState) applyStructureDamage(system *StarSystem, ships []Spaceship) {
+	byID := make(map[string]Spaceship)
+	for _, ship := range ships {
+		byID[ship.ID] = ship
+	}
+	
+	var standing []Structure
+	for _, structure := range system.Structures {
+		ship, fought := byID[structure.ID]
+		if !fought {
+			standing = append(standing, structure)
+			continue
+		}
+		if !ship.IsAlive() {
+			fmt.Printf("%s %s destroyed at %s\n", structure.Owner, structure.Type, system.Name)
+			gs.report(structure.Owner, "%s at %s was destroyed", structure.Type, system.Name)
+			continue
+		}
+		structure.Hull = ship.Hull
+		structure.Shields = ship.Shields
+		standing = append(standing, structure)
+	}
+	system.Structures = standing
+}
+
+// hasDefences reports whether the player still has structures standing in
+// the system. Planets can't be invaded or bombarded until they are gone.
+func (gs *GameState) hasDefences(systemID, playerID string) bool {
+	system := gs.Galaxy.GetSystemByID(systemID)
+	if system == nil {
+		return false
+	}
+	for _, structure := range system.Structures {
+		if structure.Owner == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// processBuildStructureOrder builds a structure in the system of one of the
+// player's settled planets, paid for from the planet's resources.
+func (gs *GameState) processBuildStructureOrder(order Order) {
+	planet := gs.findPlanet(order.PlanetID)
+	if planet == nil || planet.Owner != order.PlayerID || planet.Outpost {
+		return
+	}
+	
+	structureName, _ := order.Parameters["structure_type"].(string)
+	structureType, exists := structureTypes[structureName]
+	if !exists {
+		return
+	}
+	
+	system := gs.Galaxy.GetSystemByID(planet.StarSystemID)
+	for _, structure := range system.Structures {
+		if structure.Type != structureName || structure.Owner != order.PlayerID {
+			continue
+		}
+		switch structureType.Limit {
+		case LimitPerSystem:
+			return
+		case LimitPerPlanet:
+			if structure.PlanetID == planet.ID {
+				return
+			}
+		}
+	}
+	
+	if !planet.Resources.CanAfford(structureType.Cost) {
+		return
+	}
+	planet.Resources.Spend(structureType.Cost)
+	
+	system.Structures = append(system.Structures, Structure{
+		ID:         gs.newID("structure"),
+		Type:       structureName,
+		Owner:      order.PlayerID,
+		PlanetID:   planet.ID,
+		Hull:       structureType.Hull,
+		MaxHull:    structureType.Hull,
+		Shields:    structureType.Shields,
+		MaxShields: structureType.Shields,
+	})
+	fmt.Printf("Player %s built %s at %s\n", order.PlayerID, structureName, system.Name)
+}
+
+// updateStructures recharges shields and repairs hull on every structure
+// whose owner still holds a planet in the system, at the same rates as
+// ships docked at a shipyard. Structures left behind by a lost planet are
+// abandoned.
+func (gs *GameState) updateStructures() {
+	for i := range gs.Galaxy.StarSystems {
+		system := &gs.Galaxy.StarSystems[i]
+		var standing []Structure
+		for _, structure := range system.Structures {
+			if len(system.GetPlanetsByOwner(structure.Owner)) == 0 {
+				gs.report(structure.Owner, "%s at %s was abandoned", structure.Type, system.Name)
+				continue
+			}
+			ship := structure.ship()
+			ship.RechargeShields(gs.Rules.ShieldRegenRate)
+			ship.Repair(gs.Rules.ShipyardRepairRate)
+			structure.Hull = ship.Hull
+			structure.Shields = ship.Shields
+			standing = append(standing, structure)
+		}
+		system.Structures = standing
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import "testing"
+
+// newStructureTestGame returns a game with one system holding two of
+// player a's planets, well stocked, and one of player b's.
+func newStructureTestGame() *GameState {
+	system := NewStarSystem("system_test", "Test", Star{}, Coordinates{})
+	for _, planet := range []Planet{
+		NewPlanet("planet_a1", "A1", system.ID, "a", "Terran", 1, 1, true),
+		NewPlanet("planet_a2", "A2", system.ID, "a", "Terran", 1, 2, true),
+		NewPlanet("planet_b", "B", system.ID, "b", "Terran", 1, 3, true),
+	} {
+		planet.Population = 100
+		planet.Resources = Resources{Metals: 5000, Energy: 5000, Minerals: 5000, Technology: 5000}
+		system.AddPlanet(planet)
+	}
+	gs := &GameState{Reports: make(map[string][]string)}
+	gs.Galaxy.AddStarSystem(system)
+	return gs
+}
+
+func TestProcessBuildStructureOrder(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      []Structure
+		setup         func(gs *GameState)
+		player        string
+		planet        string
+		structureType string
+		wantBuilt     bool
+	}{
+		{name: "first shield", player: "a", planet: "planet_a1", structureType: "PlanetaryShield", wantBuilt: true},
+		{
+			name:     "second shield on the same planet",
+			existing: []Structure{{Type: "PlanetaryShield", Owner: "a", PlanetID: "planet_a1"}},
+			player:   "a", planet: "planet_a1", structureType: "PlanetaryShield",
+		},
+		{
+			name:     "shield on another planet",
+			existing: []Structure{{Type: "PlanetaryShield", Owner: "a", PlanetID: "planet_a1"}},
+			player:   "a", planet: "planet_a2", structureType: "PlanetaryShield", wantBuilt: true,
+		},
+		{
+			name:     "second starbase in the system",
+			existing: []Structure{{Type: "Starbase", Owner: "a", PlanetID: "planet_a1"}},
+			player:   "a", planet: "planet_a2", structureType: "Starbase",
+		},
+		{
+			name:     "starbase beside another player's",
+			existing: []Structure{{Type: "Starbase", Owner: "b", PlanetID: "planet_b"}},
+			player:   "a", planet: "planet_a1", structureType: "Starbase", wantBuilt: true,
+		},
+		{
+			name: "batteries have no limit",
+			existing: []Structure{
+				{Type: "DefenceBattery", Owner: "a", PlanetID: "planet_a1"},
+				{Type: "DefenceBattery", Owner: "a", PlanetID: "planet_a1"},
+			},
+			player: "a", planet: "planet_a1", structureType: "DefenceBattery", wantBuilt: true,
+		},
+		{name: "unknown structure", player: "a", planet: "planet_a1", structureType: "DeathStar"},
+		{name: "another player's planet", player: "a", planet: "planet_b", structureType: "DefenceBattery"},
+		{
+			name: "from an outpost",
+			setup: func(gs *GameState) {
+				gs.findPlanet("planet_a1").Outpost = true
+			},
+			player: "a", planet: "planet_a1", structureType: "DefenceBattery",
+		},
+		{
+			name: "can't afford it",
+			setup: func(gs *GameState) {
+				gs.findPlanet("planet_a1").Resources = Resources{}
+			},
+			player: "a", planet: "planet_a1", structureType: "DefenceBattery",
+		},
+	}
+	
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gs := newStructureTestGame()
+			system := &gs.Galaxy.StarSystems[0]
+			system.Structures = append(system.Structures, test.existing...)
+			if test.setup != nil {
+				test.setup(gs)
+			}
+			before := gs.findPlanet(test.planet).Resources
+			
+			gs.processBuildStructureOrder(Order{
+				PlayerID:   test.player,
+				OrderType:  string(OrderBuildStructure),
+				PlanetID:   test.planet,
+				Parameters: map[string]interface{}{"structure_type": test.structureType},
+			})
+			
+			built := len(system.Structures) - len(test.existing)
+			if test.wantBuilt != (built == 1) || built > 1 {
+				t.Fatalf("built %d structures, want built = %v", built, test.wantBuilt)
+			}
+			after := gs.findPlanet(test.planet).Resources
+			if !test.wantBuilt {
+				if after != before {
+					t.Errorf("resources changed from %+v to %+v without building", before, after)
+				}
+				return
+			}
+			structure := system.Structures[len(system.Structures)-1]
+			if structure.Type != test.structureType || structure.Owner != test.player || structure.PlanetID != test.planet {
+				t.Errorf("built %+v", structure)
+			}
+			if structure.Hull != structureTypes[test.structureType].Hull {
+				t.Errorf("hull = %d, want %d", structure.Hull, structureTypes[test.structureType].Hull)
+			}
+			if after == before {
+				t.Errorf("structure was built for free")
+			}
+		})
+	}
+}
+
+func TestStructuresDefendTheirSystem(t *testing.T) {
+	gs := newStructureTestGame()
+	gs.Relations = make(map[string]string)
+	system := &gs.Galaxy.StarSystems[0]
+	system.Structures = []Structure{{ID: "structure_1", Type: "Starbase", Owner: "a", PlanetID: "planet_a1", Hull: 600, MaxHull: 600}}
+	gs.Fleets = []Fleet{NewFleet("fleet_b", "b", system.ID, []Spaceship{NewSpaceship("ship_b", "Scout", "b", 10, 0, 0, 0, 5)})}
+	
+	// An invasion has to wait until the starbase is gone
+	gs.processInvadeOrder(Order{PlayerID: "b", PlanetID: "planet_a2"})
+	if len(gs.Reports["b"]) != 1 || gs.findPlanet("planet_a2").Owner != "a" {
+		t.Fatalf("invasion went ahead past a starbase: reports %v", gs.Reports["b"])
+	}
+	
+	gs.resolveCombat()
+	
+	if len(gs.Fleets) != 0 {
+		t.Errorf("fleets = %+v, want the scout destroyed by the starbase", gs.Fleets)
+	}
+	if len(system.Structures) != 1 || system.Structures[0].Hull != 600 {
+		t.Errorf("structures = %+v, want the starbase untouched", system.Structures)
+	}
+}
+
+func TestUpdateStructures(t *testing.T) {
+	gs := newStructureTestGame()
+	gs.Rules = DefaultGameRules()
+	system := &gs.Galaxy.StarSystems[0]
+	system.Structures = []Structure{
+		{ID: "structure_1", Type: "DefenceBattery", Owner: "a", PlanetID: "planet_a1", Hull: 50, MaxHull: 150},
+		{ID: "structure_2", Type: "DefenceBattery", Owner: "c", Hull: 150, MaxHull: 150},
+	}
+	
+	gs.updateStructures()
+	
+	if len(system.Structures) != 1 || system.Structures[0].ID != "structure_1" {
+		t.Fatalf("structures = %+v, want c's battery abandoned", system.Structures)
+	}
+	if hull := system.Structures[0].Hull; hull <= 50 {
+		t.Errorf("hull = %d, want the battery repaired", hull)
+	}
+	if len(gs.Reports["c"]) != 1 {
+		t.Errorf("reports = %v, want c told of the abandoned battery", gs.Reports)
+	}
+}
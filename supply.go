@@ -0,0 +1,124 @@
+package main
+
+import "fmt"
+
+const (
+	systemSupplyRange   = 2
+	starbaseSupplyRange = 3
+	attritionPercent    = 10
+)
+
+// SupplyNetwork returns the systems in which a player's fleets are in
+// supply. Supply reaches a few jumps along the hyperlanes from every system
+// the player controls, further from starbases, but never into systems
+// controlled by enemies.
+func (gs *GameState) SupplyNetwork(playerID string) map[string]bool {
+	reach := make(map[string]int)
+	queue := []string{}
+	
+	for _, system := range gs.Galaxy.StarSystems {
+		supplyRange := -1
+		if system.ControlledBy == playerID {
+			supplyRange = systemSupplyRange
+		}
+		if system.HasStructure(playerID, "Starbase") {
+			supplyRange = starbaseSupplyRange
+		}
+		if supplyRange >= 0 {
+			reach[system.ID] = supplyRange
+			queue = append(queue, system.ID)
+		}
+	}
+	
+	// Breadth-first, keeping the furthest remaining reach seen in each system
+	for len(queue) > 0 {
+		system := gs.Galaxy.GetSystemByID(queue[0])
+		queue = queue[1:]
+		
+		remaining := reach[system.ID]
+		if remaining == 0 {
+			continue
+		}
+		
+		for _, neighbourID := range system.Connections {
+			neighbour := gs.Galaxy.GetSystemByID(neighbourID)
+			if neighbour.ControlledBy != "" && gs.areHostile(neighbour.ControlledBy, playerID) {
+				continue
+			}
+			if known, seen := reach[neighbourID]; !seen || known < remaining-1 {
+				reach[neighbourID] = remaining - 1
+				queue = append(queue, neighbourID)
+			}
+		}
+	}
+	
+	network := make(map[string]bool)
+	for systemID := range reach {
+		network[systemID] = true
+	}
+	return network
+}
+
+// updateSupply refuels fleets inside their owner's supply network, counting
+// a fleet in transit as inside when both ends of its hop are. Fleets outside
+// it burn fuel, twice as fast while travelling, and once their tanks are dry
+// their ships lose hull every turn until they get back.
+func (gs *GameState) updateSupply() {
+	fmt.Println("Updating supply...")
+	
+	networks := make(map[string]map[string]bool)
+	for i := range gs.Fleets {
+		fleet := &gs.Fleets[i]
+		if networks[fleet.Owner] == nil {
+			networks[fleet.Owner] = gs.SupplyNetwork(fleet.Owner)
+		}
+		
+		network := networks[fleet.Owner]
+		fleet.InSupply = network[fleet.Location] && (!fleet.InTransit() || network[fleet.Destination])
+		if fleet.InSupply {
+			fleet.Refuel()
+			continue
+		}
+		
+		burn := 1
+		if fleet.InTransit() {
+			burn = 2
+		}
+		if fleet.BurnFuel(burn) {
+			continue
+		}
+		
+		lost := 0
+		for j := range fleet.Ships {
+			ship := &fleet.Ships[j]
+			if !ship.IsAlive() {
+				continue
+			}
+			ship.Hull -= max(1, ship.MaxHull*attritionPercent/100)
+			if ship.Hull <= 0 {
+				ship.Hull = 0
+				ship.IsDestroyed = true
+				lost++
+			}
+		}
+		gs.report(fleet.Owner, "Fleet %s is out of fuel and suffering attrition", fleet.ID)
+		if lost > 0 {
+			gs.report(fleet.Owner, "Fleet %s lost %d ships to attrition", fleet.ID, lost)
+		}
+	}
+	
+	gs.removeDestroyedShips()
+}
+
+// removeDestroyedShips clears wrecks out of every fleet and disbands fleets
+// with nothing left.
+func (gs *GameState) removeDestroyedShips() {
+	remaining := []Fleet{}
+	for _, fleet := range gs.Fleets {
+		fleet.Ships = fleet.GetAliveShips()
+		if len(fleet.Ships) > 0 {
+			remaining = append(remaining, fleet)
+		}
+	}
+	gs.Fleets = remaining
+}
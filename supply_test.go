@@ -0,0 +1,165 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// newSupplyTestGame returns a game on a line of systems s1 to s6, with s1
+// controlled by player a.
+func newSupplyTestGame() *GameState {
+	ids := []string{"s1", "s2", "s3", "s4", "s5", "s6"}
+	coordinates := make(map[string]Coordinates)
+	var lanes [][2]string
+	for i, id := range ids {
+		coordinates[id] = Coordinates{X: float64(i * 10)}
+		if i > 0 {
+			lanes = append(lanes, [2]string{ids[i-1], id})
+		}
+	}
+	gs := &GameState{
+		Galaxy:    newTestGalaxy(coordinates, lanes),
+		Reports:   make(map[string][]string),
+		Relations: make(map[string]string),
+	}
+	gs.Galaxy.GetSystemByID("s1").ControlledBy = "a"
+	return gs
+}
+
+func TestSupplyNetwork(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(gs *GameState)
+		want  []string
+	}{
+		{
+			name:  "two jumps from a controlled system",
+			setup: func(gs *GameState) {},
+			want:  []string{"s1", "s2", "s3"},
+		},
+		{
+			name: "three jumps from a starbase",
+			setup: func(gs *GameState) {
+				system := gs.Galaxy.GetSystemByID("s1")
+				system.Structures = append(system.Structures, Structure{Type: "Starbase", Owner: "a"})
+			},
+			want: []string{"s1", "s2", "s3", "s4"},
+		},
+		{
+			name: "blocked by an enemy system",
+			setup: func(gs *GameState) {
+				gs.Galaxy.GetSystemByID("s2").ControlledBy = "b"
+			},
+			want: []string{"s1"},
+		},
+		{
+			name: "passes through a friendly system",
+			setup: func(gs *GameState) {
+				gs.Galaxy.GetSystemByID("s2").ControlledBy = "b"
+				gs.setRelation("a", "b", StatusPeace)
+			},
+			want: []string{"s1", "s2", "s3"},
+		},
+		{
+			name: "reach is counted from the nearest source",
+			setup: func(gs *GameState) {
+				gs.Galaxy.GetSystemByID("s5").ControlledBy = "a"
+			},
+			want: []string{"s1", "s2", "s3", "s4", "s5", "s6"},
+		},
+	}
+	
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gs := newSupplyTestGame()
+			test.setup(gs)
+			
+			var got []string
+			for id := range gs.SupplyNetwork("a") {
+				got = append(got, id)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("SupplyNetwork = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUpdateSupply(t *testing.T) {
+	tests := []struct {
+		name         string
+		location     string
+		destination  string
+		fuel         int
+		wantInSupply bool
+		wantFuel     int
+		wantHull     int
+	}{
+		{name: "idle in supply refuels", location: "s3", fuel: 2, wantInSupply: true, wantFuel: 10, wantHull: 100},
+		{name: "idle out of supply burns fuel", location: "s4", fuel: 5, wantFuel: 4, wantHull: 100},
+		{name: "travelling inside the network", location: "s2", destination: "s3", fuel: 5, wantInSupply: true, wantFuel: 10, wantHull: 100},
+		{name: "travelling out of the network burns double", location: "s3", destination: "s4", fuel: 5, wantFuel: 3, wantHull: 100},
+		{name: "dry tanks out of supply suffer attrition", location: "s5", fuel: 0, wantFuel: 0, wantHull: 90},
+	}
+	
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gs := newSupplyTestGame()
+			ship := NewSpaceship("ship_1", "Scout", "a", 100, 0, 0, 0, 5)
+			ship.Fuel = test.fuel
+			ship.MaxFuel = 10
+			fleet := NewFleet("fleet_1", "a", test.location, []Spaceship{ship})
+			fleet.Destination = test.destination
+			gs.Fleets = []Fleet{fleet}
+			
+			gs.updateSupply()
+			
+			got := gs.Fleets[0]
+			if got.InSupply != test.wantInSupply {
+				t.Errorf("InSupply = %v, want %v", got.InSupply, test.wantInSupply)
+			}
+			if got.Ships[0].Fuel != test.wantFuel {
+				t.Errorf("fuel = %d, want %d", got.Ships[0].Fuel, test.wantFuel)
+			}
+			if got.Ships[0].Hull != test.wantHull {
+				t.Errorf("hull = %d, want %d", got.Ships[0].Hull, test.wantHull)
+			}
+		})
+	}
+}
+
+func TestAttritionDestroysShips(t *testing.T) {
+	gs := newSupplyTestGame()
+	ship := NewSpaceship("ship_1", "Wreck", "a", 100, 0, 0, 0, 5)
+	ship.Hull = 5
+	gs.Fleets = []Fleet{NewFleet("fleet_1", "a", "s6", []Spaceship{ship})}
+	
+	gs.updateSupply()
+	
+	if len(gs.Fleets) != 0 {
+		t.Errorf("fleets = %+v, want the wrecked fleet disbanded", gs.Fleets)
+	}
+	if len(gs.Reports["a"]) != 2 {
+		t.Errorf("reports = %v, want attrition and the loss", gs.Reports["a"])
+	}
+}
+
+func TestFleetWithoutFuelStaysPut(t *testing.T) {
+	gs := newSupplyTestGame()
+	gs.Fleets = []Fleet{NewFleet("fleet_1", "a", "s4", []Spaceship{NewSpaceship("ship_1", "Scout", "a", 100, 0, 0, 0, 10)})}
+	gs.Fleets[0].Route = []string{"s5"}
+	
+	gs.advanceFleets()
+	if gs.Fleets[0].InTransit() {
+		t.Fatalf("fleet left for s5 with empty tanks")
+	}
+	
+	// Heading back into supply is always allowed
+	gs.Fleets[0].Route = []string{"s3"}
+	gs.advanceFleets()
+	if gs.Fleets[0].Location != "s3" {
+		t.Errorf("location = %q, want s3", gs.Fleets[0].Location)
+	}
+}
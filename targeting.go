@@ -0,0 +1,81 @@
+package main
+
+import "math/rand"
+
+// TargetingStrategy picks which enemy a ship shoots at. Fleets choose one
+// through their doctrine.
+type TargetingStrategy interface {
+	Name() string
+	SelectTarget(attacker *Spaceship, enemies []*Spaceship, rng *rand.Rand) *Spaceship
+}
+
+const defaultDoctrine = "random"
+
+var targetingStrategies = map[string]TargetingStrategy{
+	"random":              randomTargeting{},
+	"weakest":             scoredTargeting{name: "weakest", score: func(s *Spaceship) int { return -(s.Hull + s.Shields) }},
+	"highest-threat":      scoredTargeting{name: "highest-threat", score: func(s *Spaceship) int { return s.Attack }},
+	"shields-first":       scoredTargeting{name: "shields-first", score: func(s *Spaceship) int { return s.Shields }},
+	"capital-ships-first": scoredTargeting{name: "capital-ships-first", score: func(s *Spaceship) int { return s.MaxHull }},
+}
+
+// TargetingStrategyFor returns the strategy for a doctrine, falling back to
+// random targeting for an unknown or empty doctrine.
+func TargetingStrategyFor(doctrine string) TargetingStrategy {
+	if strategy, exists := targetingStrategies[doctrine]; exists {
+		return strategy
+	}
+	return targetingStrategies[defaultDoctrine]
+}
+
+// randomTargeting spreads fire uniformly over the enemy.
+type randomTargeting struct{}
+
+func (randomTargeting) Name() string { return "random" }
+
+func (randomTargeting) SelectTarget(attacker *Spaceship, enemies []*Spaceship, rng *rand.Rand) *Spaceship {
+	return enemies[rng.Intn(len(enemies))]
+}
+
+// scoredTargeting shoots at the enemy with the highest score, picking at
+// random between ties. The built-in scores are:
+//
+//	weakest              least hull and shields left, to finish ships off
+//	highest-threat       most attack
+//	shields-first        most shields, to strip them before the hull
+//	capital-ships-first  biggest hull
+type scoredTargeting struct {
+	name  string
+	score func(*Spaceship) int
+}
+
+func (s scoredTargeting) Name() string { return s.name }
+
+func (s scoredTargeting) SelectTarget(attacker *Spaceship, enemies []*Spaceship, rng *rand.Rand) *Spaceship {
+	var best []*Spaceship
+	bestScore := 0
+	for _, enemy := range enemies {
+		score := s.score(enemy)
+		if len(best) == 0 || score > bestScore {
+			best = []*Spaceship{enemy}
+			bestScore = score
+		} else if score == bestScore {
+			best = append(best, enemy)
+		}
+	}
+	return best[rng.Intn(len(best))]
+}
+
+func (gs *GameState) processDoctrineOrder(order Order) {
+	fleetID, _ := order.Parameters["fleet_id"].(string)
+	doctrine, _ := order.Parameters["doctrine"].(string)
+	fleet := gs.findFleet(fleetID)
+	if fleet == nil || fleet.Owner != order.PlayerID {
+		return
+	}
+	if _, exists := targetingStrategies[doctrine]; !exists {
+		return
+	}
+	
+	fleet.Doctrine = doctrine
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTargetingStrategies(t *testing.T) {
+	wounded := NewSpaceship("wounded", "Wounded", "b", 100, 0, 0, 5, 5)
+	wounded.Hull = 5
+	enemies := []Spaceship{
+		wounded,
+		NewSpaceship("glass", "Glass Cannon", "b", 10, 0, 0, 50, 5),
+		NewSpaceship("tank", "Tank", "b", 200, 0, 0, 5, 5),
+		NewSpaceship("shielded", "Shielded", "b", 50, 0, 40, 10, 5),
+	}
+	want := map[string]string{
+		"random":              "wounded",
+		"weakest":             "wounded",
+		"highest-threat":      "glass",
+		"shields-first":       "shielded",
+		"capital-ships-first": "tank",
+	}
+	
+	for doctrine, target := range want {
+		var pointers []*Spaceship
+		for i := range enemies {
+			pointers = append(pointers, &enemies[i])
+		}
+		strategy := TargetingStrategyFor(doctrine)
+		got := strategy.SelectTarget(&Spaceship{Owner: "a"}, pointers, rand.New(alwaysHits{}))
+		if got.ID != target {
+			t.Errorf("%s picked %s, want %s", doctrine, got.ID, target)
+		}
+		if strategy.Name() != doctrine {
+			t.Errorf("strategy for %s is named %s", doctrine, strategy.Name())
+		}
+	}
+	
+	if name := TargetingStrategyFor("kamikaze").Name(); name != "random" {
+		t.Errorf("unknown doctrine fell back to %s, want random", name)
+	}
+}
+
+func TestBattleRecordsTargetingRule(t *testing.T) {
+	attacker := testFleet("fleet_a", "a", NewSpaceship("ship_a", "Ship", "a", 10, 0, 0, 25, 5))
+	attacker.Doctrine = "highest-threat"
+	fleets := []Fleet{
+		attacker,
+		testFleet("fleet_b", "b", NewSpaceship("ship_b", "Ship", "b", 10, 0, 0, 0, 3)),
+	}
+	
+	result := RunBattle(fleets, BattleConfig{Rand: rand.New(alwaysHits{})})
+	
+	if rule := result.Rounds[0].Attacks[0].Rule; rule != "highest-threat" {
+		t.Errorf("attack rule = %q, want highest-threat", rule)
+	}
+}
+
+func TestDoctrineOrder(t *testing.T) {
+	gs := &GameState{Fleets: []Fleet{testFleet("fleet_1", "a")}}
+	
+	gs.processDoctrineOrder(Order{PlayerID: "a", Parameters: map[string]interface{}{"fleet_id": "fleet_1", "doctrine": "kamikaze"}})
+	gs.processDoctrineOrder(Order{PlayerID: "b", Parameters: map[string]interface{}{"fleet_id": "fleet_1", "doctrine": "weakest"}})
+	if doctrine := gs.Fleets[0].Doctrine; doctrine != defaultDoctrine {
+		t.Fatalf("doctrine = %q after invalid orders, want %q", doctrine, defaultDoctrine)
+	}
+	
+	gs.processDoctrineOrder(Order{PlayerID: "a", Parameters: map[string]interface{}{"fleet_id": "fleet_1", "doctrine": "weakest"}})
+	if doctrine := gs.Fleets[0].Doctrine; doctrine != "weakest" {
+		t.Errorf("doctrine = %q, want weakest", doctrine)
+	}
+}
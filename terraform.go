@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	habitableTemperature = 15
+	habitableAtmosphere  = "Oxygen-Nitrogen"
+)
+
+type TerraformProject struct {
+	PlanetID       string
+	PlayerID       string
+	TurnsRemaining int
+	CostPerTurn    Resources
+}
+
+type terraformProfile struct {
+	Difficulty float64
+	Technology string
+}
+
+// terraformProfiles lists the planet types that can be terraformed. Gas
+// giants have no surface to work on and are left out.
+var terraformProfiles = map[string]terraformProfile{
+	"Ocean World": {Difficulty: 0.8, Technology: "Terraforming"},
+	"Desert":      {Difficulty: 1.0, Technology: "Terraforming"},
+	"Rocky":       {Difficulty: 1.2, Technology: "Terraforming"},
+	"Terrestrial": {Difficulty: 1.0, Technology: "Terraforming"},
+	"Ice World":   {Difficulty: 1.5, Technology: "AdvancedTerraforming"},
+}
+
+// terraformPlan works out how long terraforming a planet takes and what it
+// costs each turn. Bigger and harsher worlds take longer and cost more.
+func terraformPlan(planet *Planet) (turns int, costPerTurn Resources, ok bool) {
+	profile, exists := terraformProfiles[planet.PlanetType]
+	if !exists {
+		return 0, Resources{}, false
+	}
+	
+	scale := profile.Difficulty * planet.Size
+	temperatureGap := math.Abs(float64(planet.Temperature - habitableTemperature))
+	
+	turns = int(math.Ceil(scale*4 + temperatureGap/50))
+	costPerTurn = Resources{
+		Metals:     int(20 * scale),
+		Energy:     int(30 * scale),
+		Minerals:   int(15 * scale),
+		Technology: int(5 * scale),
+	}
+	return max(turns, 1), costPerTurn, true
+}
+
+func (gs *GameState) processTerraformOrder(order Order) {
+	planet := gs.findPlanet(order.PlanetID)
+	if planet == nil || planet.Habitable {
+		return
+	}
+	if planet.Owner != "" && planet.Owner != order.PlayerID {
+		return
+	}
+	
+	system := gs.Galaxy.GetSystemByID(planet.StarSystemID)
+	if system == nil || len(system.GetPlanetsByOwner(order.PlayerID)) == 0 {
+		return
+	}
+	
+	for _, project := range gs.TerraformProjects {
+		if project.PlanetID == planet.ID {
+			return
+		}
+	}
+	
+	turns, costPerTurn, ok := terraformPlan(planet)
+	if !ok || !gs.HasTechnology(order.PlayerID, terraformProfiles[planet.PlanetType].Technology) {
+		return
+	}
+	
+	gs.TerraformProjects = append(gs.TerraformProjects, TerraformProject{
+		PlanetID:       planet.ID,
+		PlayerID:       order.PlayerID,
+		TurnsRemaining: turns,
+		CostPerTurn:    costPerTurn,
+	})
+	fmt.Printf("Player %s started terraforming %s (%d turns)\n", order.PlayerID, planet.Name, turns)
+}
+
+// updateTerraforming advances every project by one turn. A project that
+// can't be paid for this turn stalls until the resources are there.
+func (gs *GameState) updateTerraforming() {
+	fmt.Println("Updating terraforming...")
+	
+	remaining := []TerraformProject{}
+	for _, project := range gs.TerraformProjects {
+		planet := gs.findPlanet(project.PlanetID)
+		if planet == nil || planet.Owner != "" && planet.Owner != project.PlayerID {
+			continue
+		}
+		
+		payer := gs.findTerraformPayer(planet, project)
+		if payer == nil {
+			fmt.Printf("Terraforming of %s stalled for lack of resources\n", planet.Name)
+			remaining = append(remaining, project)
+			continue
+		}
+		payer.Resources.Spend(project.CostPerTurn)
+		
+		// Close an even share of the temperature gap each turn
+		gap := habitableTemperature - planet.Temperature
+		planet.Temperature += gap / project.TurnsRemaining
+		project.TurnsRemaining--
+		
+		if project.TurnsRemaining <= 0 {
+			planet.Temperature = habitableTemperature
+			planet.Atmosphere = habitableAtmosphere
+			planet.Habitable = true
+			fmt.Printf("Player %s finished terraforming %s\n", project.PlayerID, planet.Name)
+			continue
+		}
+		
+		if planet.Atmosphere == "None" {
+			planet.Atmosphere = "Thin"
+		}
+		remaining = append(remaining, project)
+	}
+	gs.TerraformProjects = remaining
+}
+
+// findTerraformPayer picks the planet that pays for a project this turn: the
+// player's first planet in the same system that can cover the cost.
+func (gs *GameState) findTerraformPayer(planet *Planet, project TerraformProject) *Planet {
+	system := gs.Galaxy.GetSystemByID(planet.StarSystemID)
+	if system == nil {
+		return nil
+	}
+	
+	for i := range system.Planets {
+		candidate := &system.Planets[i]
+		if candidate.Owner == project.PlayerID && candidate.Resources.CanAfford(project.CostPerTurn) {
+			return candidate
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// WeaponType describes how a kind of weapon behaves in combat.
+// ShieldMultiplier scales damage against shields, and ArmorMultiplier
+// scales how much of the target's armor counts against what gets through.
+// Accuracy is the base hit chance, adjusted by SpeedFactor for every point
+// of speed the attacker has over the target. Interceptable weapons can be
+// shot down by point defence. Ranges lists the range bands the weapon can
+// fire at when battles use engagement ranges.
+type WeaponType struct {
+	Name             string
+	ShieldMultiplier float64
+	ArmorMultiplier  float64
+	Accuracy         float64
+	SpeedFactor      float64
+	Interceptable    bool
+	Ranges           []string
+}
+
+type Weapon struct {
+	Type   string
+	Damage int
+}
+
+const (
+	pointDefenceInterceptChance = 0.1
+	maxInterceptChance          = 0.75
+	minHitChance                = 0.1
+	maxHitChance                = 0.95
+)
+
+var weaponTypes = map[string]WeaponType{
+	"kinetic": {
+		Name: "kinetic", ShieldMultiplier: 0.75, ArmorMultiplier: 0.5,
+		Accuracy: 0.7, SpeedFactor: 0.04,
+		Ranges: []string{RangeShort, RangeMedium},
+	},
+	"energy": {
+		Name: "energy", ShieldMultiplier: 1.5, ArmorMultiplier: 1.5,
+		Accuracy: 0.8, SpeedFactor: 0.02,
+		Ranges: []string{RangeShort, RangeMedium, RangeLong},
+	},
+	"missile": {
+		Name: "missile", ShieldMultiplier: 1.0, ArmorMultiplier: 1.0,
+		Accuracy: 0.85, SpeedFactor: 0.01, Interceptable: true,
+		Ranges: []string{RangeMedium, RangeLong},
+	},
+	"fighter": {
+		Name: "fighter", ShieldMultiplier: 1.0, ArmorMultiplier: 1.0,
+		Accuracy: 0.9, SpeedFactor: 0, Interceptable: true,
+		Ranges: []string{RangeShort, RangeMedium, RangeLong},
+	},
+}
+
+// WeaponList returns the ship's weapons. Ships built without a weapon list
+// fire their Attack as a single kinetic weapon.
+func (s *Spaceship) WeaponList() []Weapon {
+	if len(s.Weapons) == 0 && s.Attack > 0 {
+		return []Weapon{{Type: "kinetic", Damage: s.Attack}}
+	}
+	return s.Weapons
+}
+
+// hitChance is the chance for a weapon to hit a target. Faster attackers
+// find it easier to hit slower targets, and the reverse.
+func hitChance(weapon WeaponType, attacker, target *Spaceship) float64 {
+	chance := weapon.Accuracy + float64(attacker.Speed-target.Speed)*weapon.SpeedFactor
+	return math.Max(minHitChance, math.Min(maxHitChance, chance))
+}
+
+// intercepted reports whether the target's point defence shoots down an
+// incoming weapon.
+func intercepted(weapon WeaponType, target *Spaceship, rng *rand.Rand) bool {
+	if !weapon.Interceptable || target.PointDefense == 0 {
+		return false
+	}
+	chance := math.Min(maxInterceptChance, float64(target.PointDefense)*pointDefenceInterceptChance)
+	return rng.Float64() < chance
+}
+
+// TakeWeaponDamage applies a hit from a weapon of the given type. Shields
+// soak up damage first, scaled by the weapon's effect on shields; whatever
+// gets through is reduced by the target's armor, scaled by the weapon's
+// effect on armor. It returns the hull damage dealt, no more than the hull
+// the ship had left.
+func (s *Spaceship) TakeWeaponDamage(damage int, weapon WeaponType) int {
+	remaining := float64(damage)
+	
+	if s.Shields > 0 {
+		shieldDamage := remaining * weapon.ShieldMultiplier
+		if shieldDamage >= float64(s.Shields) {
+			remaining = (shieldDamage - float64(s.Shields)) / weapon.ShieldMultiplier
+			s.Shields = 0
+		} else {
+			s.Shields -= int(shieldDamage)
+			remaining = 0
+		}
+	}
+	
+	hullDamage := int(remaining - float64(s.Armor)*weapon.ArmorMultiplier)
+	if hullDamage <= 0 {
+		return 0
+	}
+	hullDamage = min(hullDamage, s.Hull)
+	
+	s.Hull -= hullDamage
+	if s.Hull <= 0 {
+		s.Hull = 0
+		s.IsDestroyed = true
+	}
+	return hullDamage
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTakeWeaponDamage(t *testing.T) {
+	tests := []struct {
+		weapon      string
+		damage      int
+		wantShields int
+		wantHull    int
+	}{
+		// Kinetic rounds are weak against shields but half ignore armor
+		{"kinetic", 40, 0, 92},
+		// Energy weapons strip shields fast but armor stops more of them
+		{"energy", 40, 0, 89},
+		{"energy", 10, 5, 100},
+		{"missile", 40, 0, 90},
+		// Overkill only counts the hull there was to take
+		{"missile", 500, 0, 0},
+	}
+	
+	for _, test := range tests {
+		ship := NewSpaceship("target", "Target", "b", 100, 10, 20, 0, 5)
+		dealt := ship.TakeWeaponDamage(test.damage, weaponTypes[test.weapon])
+		if ship.Shields != test.wantShields || ship.Hull != test.wantHull {
+			t.Errorf("%d %s damage left shields %d, hull %d, want %d and %d",
+				test.damage, test.weapon, ship.Shields, ship.Hull, test.wantShields, test.wantHull)
+		}
+		if dealt != 100-test.wantHull {
+			t.Errorf("%d %s damage reported %d hull damage, want %d", test.damage, test.weapon, dealt, 100-test.wantHull)
+		}
+	}
+}
+
+func TestHitChance(t *testing.T) {
+	ship := func(speed int) *Spaceship {
+		return &Spaceship{Speed: speed}
+	}
+	tests := map[string]struct {
+		chance float64
+		want   float64
+	}{
+		"even speeds":        {hitChance(weaponTypes["kinetic"], ship(5), ship(5)), 0.7},
+		"faster attacker":    {hitChance(weaponTypes["energy"], ship(10), ship(5)), 0.9},
+		"capped":             {hitChance(weaponTypes["kinetic"], ship(20), ship(0)), maxHitChance},
+		"never below floor":  {hitChance(weaponTypes["kinetic"], ship(0), ship(30)), minHitChance},
+		"fighters ignore it": {hitChance(weaponTypes["fighter"], ship(0), ship(30)), 0.9},
+	}
+	
+	for name, test := range tests {
+		if math.Abs(test.chance-test.want) > 1e-9 {
+			t.Errorf("%s: hit chance = %v, want %v", name, test.chance, test.want)
+		}
+	}
+}
+
+func TestPointDefenceInterceptsMissiles(t *testing.T) {
+	rng := rand.New(alwaysHits{})
+	target := NewSpaceship("target", "Target", "b", 100, 0, 0, 0, 5)
+	if intercepted(weaponTypes["missile"], &target, rng) {
+		t.Errorf("missile intercepted by a ship without point defence")
+	}
+	
+	target.PointDefense = 3
+	if !intercepted(weaponTypes["missile"], &target, rng) {
+		t.Errorf("missile got through point defence on a certain roll")
+	}
+	if intercepted(weaponTypes["energy"], &target, rng) {
+		t.Errorf("point defence intercepted an energy weapon")
+	}
+}
+
+func TestBattleFiresEveryWeapon(t *testing.T) {
+	gunship := NewSpaceship("gunship", "Gunship", "a", 100, 0, 0, 0, 5)
+	gunship.Weapons = []Weapon{{Type: "kinetic", Damage: 5}, {Type: "missile", Damage: 5}}
+	target := NewSpaceship("target", "Target", "b", 100, 0, 0, 0, 3)
+	target.PointDefense = 1
+	
+	result := RunBattle([]Fleet{testFleet("fleet_a", "a", gunship), testFleet("fleet_b", "b", target)},
+		BattleConfig{Rand: rand.New(alwaysHits{}), MaxRounds: 1})
+	
+	attacks := result.Rounds[0].Attacks
+	if len(attacks) != 2 {
+		t.Fatalf("%d attacks, want one per weapon", len(attacks))
+	}
+	if attacks[0].Weapon != "kinetic" || !attacks[0].Hit {
+		t.Errorf("first attack = %+v, want a kinetic hit", attacks[0])
+	}
+	if attacks[1].Weapon != "missile" || !attacks[1].Intercepted || attacks[1].Damage != 0 {
+		t.Errorf("second attack = %+v, want an intercepted missile", attacks[1])
+	}
+	if hull := result.Fleets[1].Ships[0].Hull; hull != 95 {
+		t.Errorf("target hull = %d, want 95", hull)
+	}
+	
+	// A ship with no weapon list fires its attack as a kinetic weapon
+	if weapons := (&Spaceship{Attack: 7}).WeaponList(); len(weapons) != 1 || weapons[0] != (Weapon{Type: "kinetic", Damage: 7}) {
+		t.Errorf("fallback weapons = %v", weapons)
+	}
+}